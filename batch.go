@@ -0,0 +1,94 @@
+package mdb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+)
+
+// BatchStatement is a statement of a batch along with its arguments.
+// Arguments may be given with sql.Named.
+type BatchStatement struct {
+	Query string
+	Args  []interface{}
+}
+
+// BatchOf returns a batch running query once for every set of arguments.
+func BatchOf(query string, argSets ...[]interface{}) []BatchStatement {
+	stmts := make([]BatchStatement, len(argSets))
+	for i, args := range argSets {
+		stmts[i] = BatchStatement{Query: query, Args: args}
+	}
+	return stmts
+}
+
+// BatchError reports the statement of a batch, or of a multi-statement
+// script, which failed.
+type BatchError struct {
+	Index int // Position of the statement in the batch
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("mdb: batch statement %d: %v", e.Index, e.Err)
+}
+
+func (e *BatchError) Unwrap() error { return e.Err }
+
+var errNotMDBConn = errors.New("mdb: not an mdb driver connection")
+
+// ExecBatch executes the statements of a batch in order on a connection and
+// returns the result of each statement. The connection is the one handed
+// out by database/sql's Conn.Raw:
+//
+//	err = conn.Raw(func(driverConn interface{}) (err error) {
+//		results, err = mdb.ExecBatch(ctx, driverConn, stmts)
+//		return
+//	})
+//
+// Execution stops at the first failing statement. The results of the
+// statements before it are returned along with a *BatchError.
+//
+// The MDB protocol has no batch RPC, so every statement is sent as its own
+// Exec request on the session of the connection.
+func ExecBatch(ctx context.Context, driverConn interface{}, stmts []BatchStatement) ([]Result, error) {
+	db, ok := driverConn.(*Conn)
+	if !ok {
+		return nil, errNotMDBConn
+	}
+
+	results := make([]Result, 0, len(stmts))
+	for i, stmt := range stmts {
+		res, err := db.execBatchStatement(ctx, stmt)
+		if err != nil {
+			return results, &BatchError{Index: i, Err: err}
+		}
+		results = append(results, *res)
+	}
+	return results, nil
+}
+
+func (db *Conn) execBatchStatement(ctx context.Context, stmt BatchStatement) (*Result, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	args := make([]driver.NamedValue, len(stmt.Args))
+	for i, arg := range stmt.Args {
+		args[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+		if named, ok := arg.(sql.NamedArg); ok {
+			args[i].Name, args[i].Value = named.Name, named.Value
+		}
+		if err := db.CheckNamedValue(&args[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	res, err := db.ExecContext(ctx, stmt.Query, args)
+	if err != nil {
+		return nil, err
+	}
+	return res.(*Result), nil
+}
@@ -0,0 +1,62 @@
+package mdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/blockpointSystems/protocol-buffers/v1/odbc"
+)
+
+func TestExecBatch(t *testing.T) {
+	var insertId int64
+	srv := &fakeServer{
+		exec: func(req *odbc.ExecRequest) (*odbc.ExecResponse, error) {
+			if strings.Contains(req.GetStatement(), "fail") {
+				return nil, errors.New("rejected")
+			}
+			insertId++
+			return &odbc.ExecResponse{AffectedRows: 1, InsertId: insertId}, nil
+		},
+	}
+	addr, stop := startServer(t, srv)
+	defer stop()
+
+	db, err := sql.Open("mdb", "system:biglove@tcp("+addr+")/master?timeout=5s&interpolateParams=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	stmts := BatchOf("INSERT INTO ledger VALUES (:id, :memo)",
+		[]interface{}{sql.Named("id", 1), sql.Named("memo", "a")},
+		[]interface{}{sql.Named("id", 2), sql.Named("memo", "b")},
+	)
+	stmts = append(stmts, BatchStatement{Query: "INSERT INTO fail VALUES (3)"}, BatchStatement{Query: "INSERT INTO ledger VALUES (?)", Args: []interface{}{4}})
+
+	var results []Result
+	err = conn.Raw(func(driverConn interface{}) (err error) {
+		results, err = ExecBatch(context.Background(), driverConn, stmts)
+		return
+	})
+
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || batchErr.Index != 2 {
+		t.Fatalf("expected statement 2 to fail, got %v", err)
+	}
+	if want := []Result{{affectedRows: 1, insertId: 1}, {affectedRows: 1, insertId: 2}}; !reflect.DeepEqual(results, want) {
+		t.Errorf("expected %v, got %v", want, results)
+	}
+	if want := []string{`INSERT INTO ledger VALUES (1, "a")`, `INSERT INTO ledger VALUES (2, "b")`, "INSERT INTO fail VALUES (3)"}; !reflect.DeepEqual(srv.statements, want) {
+		t.Errorf("expected statements %q, got %q", want, srv.statements)
+	}
+}
@@ -0,0 +1,70 @@
+package mdb
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math/big"
+)
+
+// BigInt scans integer columns of every width, UINT64 values above the
+// range of int64 included, into a big.Int. A NULL column sets Int to nil.
+//
+//	var id mdb.BigInt
+//	err = row.Scan(&id)
+//
+// As an argument it is sent as an integer literal.
+type BigInt struct {
+	*big.Int
+}
+
+// Scan implements sql.Scanner.
+func (b *BigInt) Scan(src interface{}) error {
+	if src == nil {
+		b.Int = nil
+		return nil
+	}
+	if b.Int == nil {
+		b.Int = new(big.Int)
+	}
+
+	switch v := src.(type) {
+	case int64:
+		b.SetInt64(v)
+	case uint64:
+		b.SetUint64(v)
+	case string:
+		return b.setString(v)
+	case []byte:
+		return b.setString(string(v))
+	default:
+		return fmt.Errorf("mdb: can not scan %T into BigInt", src)
+	}
+	return nil
+}
+
+func (b *BigInt) setString(s string) error {
+	if _, ok := b.SetString(s, 10); !ok {
+		return fmt.Errorf("mdb: can not scan %q into BigInt", s)
+	}
+	return nil
+}
+
+// Value implements driver.Valuer.
+func (b BigInt) Value() (driver.Value, error) {
+	if b.Int == nil {
+		return nil, nil
+	}
+	return bigIntValue(b.Int)
+}
+
+// bigIntValue returns x as an int64, or as a uint64 if it is positive and
+// does not fit into an int64.
+func bigIntValue(x *big.Int) (driver.Value, error) {
+	switch {
+	case x.IsInt64():
+		return x.Int64(), nil
+	case x.IsUint64():
+		return x.Uint64(), nil
+	}
+	return nil, fmt.Errorf("%w: %s does not fit into a 64-bit integer", ErrOutOfRange, x)
+}
@@ -0,0 +1,95 @@
+package mdb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/blockpointSystems/protocol-buffers/v1/odbc"
+)
+
+func TestScanUint64(t *testing.T) {
+	col := make([]byte, 8)
+	binary.LittleEndian.PutUint64(col, math.MaxUint64-1)
+
+	srv := &fakeServer{
+		query: func(req *odbc.QueryRequest, stream odbc.MDBService_QueryServer) error {
+			return stream.Send(&odbc.QueryResponse{
+				RespSchema: &odbc.Schema{
+					ColumnName: []string{"id"},
+					ColumnType: []odbc.Datatype{odbc.Datatype_UINT64},
+				},
+				ResultSet: []*odbc.Row{{Columns: [][]byte{col}, NullColumnBitmap: []byte{0}}},
+				Done:      true,
+			})
+		},
+	}
+	addr, stop := startServer(t, srv)
+	defer stop()
+
+	for _, params := range []string{"", "&uint64AsString=true"} {
+		db, err := sql.Open("mdb", "system:biglove@tcp("+addr+")/master?timeout=5s"+params)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var (
+			id     uint64
+			bigId  BigInt
+			text   string
+			native interface{}
+			ctx    = context.Background()
+		)
+		for _, dest := range []interface{}{&id, &bigId, &text, &native} {
+			if err = db.QueryRowContext(ctx, "SELECT id FROM seq").Scan(dest); err != nil {
+				t.Fatalf("%s: %v", params, err)
+			}
+		}
+		db.Close()
+
+		if id != math.MaxUint64-1 || bigId.String() != "18446744073709551614" || text != "18446744073709551614" {
+			t.Errorf("%s: got %d, %s and %q", params, id, bigId, text)
+		}
+
+		want := interface{}(uint64(math.MaxUint64 - 1))
+		if params != "" {
+			want = "18446744073709551614"
+		}
+		if native != want {
+			t.Errorf("%s: expected %#v, got %#v", params, want, native)
+		}
+	}
+}
+
+func TestUint64Args(t *testing.T) {
+	huge, _ := new(big.Int).SetString("18446744073709551615", 10)
+
+	for _, arg := range []interface{}{
+		uint64(math.MaxUint64),
+		huge,
+		BigInt{huge},
+		Typed(odbc.Datatype_UINT64, "18446744073709551615"),
+	} {
+		v, err := convertArg(arg)
+		if err != nil {
+			t.Errorf("%v: %v", arg, err)
+			continue
+		}
+		if v != uint64(math.MaxUint64) {
+			t.Errorf("%v: expected uint64, got %#v", arg, v)
+		}
+	}
+
+	for _, arg := range []interface{}{
+		new(big.Int).Lsh(big.NewInt(1), 64),
+		Typed(odbc.Datatype_UINT64, "18446744073709551616"),
+		Typed(odbc.Datatype_INT64, "9223372036854775808"),
+	} {
+		if _, err := convertArg(arg); err == nil {
+			t.Errorf("%v: expected an error", arg)
+		}
+	}
+}
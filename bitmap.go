@@ -36,7 +36,7 @@ func GetBitFromByte(b byte, i int) (Bit, error) {
 //	- getBit(new byte[]{0b00000000, 0b00000001}, 15) == ONE
 func GetBitFromBytes(b []byte, i int) (Bit, error) {
 	if len(b) < 0 || 0 > i && i >= len(b)*8 {
-		fmt.Errorf("bytes.length = %d; i = %d.", len(b), i)
+		return 0, fmt.Errorf("bytes.length = %d; i = %d.", len(b), i)
 	}
 	return GetBitFromByte(b[i/8], i%8)
 }
@@ -0,0 +1,219 @@
+package mdb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// RowSource supplies the rows of a bulk insert.
+type RowSource interface {
+	// Next returns the values of the next row, in the order of the columns,
+	// or io.EOF once there are no rows left.
+	Next() ([]interface{}, error)
+}
+
+// SliceRows returns a RowSource over rows held in memory.
+func SliceRows(rows [][]interface{}) RowSource {
+	return &sliceRows{rows: rows}
+}
+
+type sliceRows struct {
+	rows [][]interface{}
+	pos  int
+}
+
+func (s *sliceRows) Next() ([]interface{}, error) {
+	if s.pos == len(s.rows) {
+		return nil, io.EOF
+	}
+	s.pos++
+	return s.rows[s.pos-1], nil
+}
+
+// BulkOption configures a bulk insert.
+type BulkOption func(*bulkOptions)
+
+type bulkOptions struct {
+	transaction bool
+	progress    func(inserted int64)
+}
+
+// BulkTransaction runs a bulk insert in a single transaction, so either
+// every row is inserted or none is.
+func BulkTransaction() BulkOption {
+	return func(o *bulkOptions) {
+		o.transaction = true
+	}
+}
+
+// BulkProgress registers a function which is called after every statement
+// with the number of rows sent so far.
+func BulkProgress(fn func(inserted int64)) BulkOption {
+	return func(o *bulkOptions) {
+		o.progress = fn
+	}
+}
+
+// BulkError reports the row at which a bulk insert failed.
+type BulkError struct {
+	// Row is the index of the failing row, or of the first row of the
+	// statement the server rejected.
+	Row int64
+	Err error
+}
+
+func (e *BulkError) Error() string {
+	return fmt.Sprintf("mdb: bulk insert failed at row %d: %v", e.Row, e.Err)
+}
+
+func (e *BulkError) Unwrap() error { return e.Err }
+
+// BulkInsert inserts the rows of a RowSource into a blockchain. The rows are
+// packed into multi-row INSERT statements which stay within the
+// maxAllowedPacket of the DSN. The blockchain and column names are written
+// into the statements as given.
+//
+// BulkInsert returns the number of rows inserted. A row which can not be
+// encoded or inserted is reported by a *BulkError.
+func BulkInsert(ctx context.Context, db *sql.DB, blockchain string, columns []string, rows RowSource, opts ...BulkOption) (inserted int64, err error) {
+	if len(columns) == 0 {
+		return 0, errors.New("mdb: bulk insert requires at least one column")
+	}
+
+	var o bulkOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	err = conn.Raw(func(driverConn interface{}) (err error) {
+		mdbConn, ok := driverConn.(*Conn)
+		if !ok {
+			return errNotMDBConn
+		}
+		inserted, err = mdbConn.bulkInsert(ctx, blockchain, columns, rows, o)
+		return
+	})
+	return
+}
+
+func (db *Conn) bulkInsert(ctx context.Context, blockchain string, columns []string, rows RowSource, o bulkOptions) (inserted int64, err error) {
+	if o.transaction {
+		var xact driver.Tx
+		xact, err = db.begin(ctx, driver.TxOptions{})
+		if err != nil {
+			return
+		}
+		defer func() {
+			if err == nil {
+				err = xact.Commit()
+			} else {
+				xact.Rollback()
+			}
+			if err != nil {
+				inserted = 0
+			}
+		}()
+	}
+
+	var (
+		header = "INSERT INTO " + blockchain + " (" + strings.Join(columns, ", ") + ") VALUES "
+		limit  = db.GetMaxPacketSize()
+
+		buf   = []byte(header)
+		row   []byte
+		first int64 // index of the first row in buf
+		next  int64 // index of the next row
+	)
+
+	flush := func() error {
+		if next == first {
+			return nil
+		}
+		if _, _, err := db.exec(ctx, string(buf)); err != nil {
+			return &BulkError{Row: first, Err: err}
+		}
+
+		inserted += next - first
+		if o.progress != nil {
+			o.progress(inserted)
+		}
+
+		buf = append(buf[:0], header...)
+		first = next
+		return nil
+	}
+
+	for {
+		if err = ctx.Err(); err != nil {
+			return
+		}
+
+		var values []interface{}
+		values, err = rows.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return inserted, &BulkError{Row: next, Err: err}
+		}
+
+		row, err = db.appendRow(row[:0], columns, values)
+		if err == nil && len(header)+len(row) > limit {
+			err = ErrPktTooLarge
+		}
+		if err != nil {
+			return inserted, &BulkError{Row: next, Err: err}
+		}
+
+		// Start a new statement once the row does not fit anymore
+		if next > first && len(buf)+len(", ")+len(row) > limit {
+			if err = flush(); err != nil {
+				return
+			}
+		}
+
+		if next > first {
+			buf = append(buf, ", "...)
+		}
+		buf = append(buf, row...)
+		next++
+	}
+
+	err = flush()
+	return
+}
+
+// appendRow appends the parenthesized literals of a row to buf.
+func (db *Conn) appendRow(buf []byte, columns []string, values []interface{}) ([]byte, error) {
+	if len(values) != len(columns) {
+		return nil, fmt.Errorf("mdb: got %d values for %d columns", len(values), len(columns))
+	}
+
+	buf = append(buf, '(')
+	for i, value := range values {
+		if i > 0 {
+			buf = append(buf, ", "...)
+		}
+
+		nv := driver.NamedValue{Ordinal: i + 1, Value: value}
+		err := db.CheckNamedValue(&nv)
+		if err == nil {
+			buf, err = db.appendValue(buf, nv.Value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", columns[i], err)
+		}
+	}
+	return append(buf, ')'), nil
+}
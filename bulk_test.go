@@ -0,0 +1,78 @@
+package mdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/blockpointSystems/protocol-buffers/v1/odbc"
+)
+
+func TestBulkInsert(t *testing.T) {
+	srv := &fakeServer{
+		exec: func(req *odbc.ExecRequest) (*odbc.ExecResponse, error) {
+			if strings.Contains(req.GetStatement(), `"bad"`) {
+				return nil, errors.New("rejected")
+			}
+			return &odbc.ExecResponse{}, nil
+		},
+	}
+	addr, stop := startServer(t, srv)
+	defer stop()
+
+	const limit = 100
+	db, err := sql.Open("mdb", fmt.Sprintf("system:biglove@tcp(%s)/master?timeout=5s&maxAllowedPacket=%d", addr, limit))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	rows := make([][]interface{}, 20)
+	for i := range rows {
+		rows[i] = []interface{}{i, fmt.Sprintf("memo %d", i)}
+	}
+
+	var progress []int64
+	inserted, err := BulkInsert(context.Background(), db, "ledger", []string{"id", "memo"}, SliceRows(rows),
+		BulkProgress(func(n int64) { progress = append(progress, n) }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inserted != 20 || progress[len(progress)-1] != 20 {
+		t.Fatalf("expected 20 rows, got %d (progress %v)", inserted, progress)
+	}
+
+	var values int
+	for _, stmt := range srv.statements {
+		if len(stmt) > limit {
+			t.Errorf("statement exceeds %d bytes: %s", limit, stmt)
+		}
+		values += strings.Count(stmt, "memo ")
+	}
+	if len(srv.statements) < 2 || len(srv.statements) != len(progress) || values != 20 {
+		t.Errorf("expected 20 rows over %d statements, got %d rows in %d statements", len(progress), values, len(srv.statements))
+	}
+
+	// A rejected statement rolls back the transaction
+	srv.statements = nil
+	rows[9][1] = "bad"
+	inserted, err = BulkInsert(context.Background(), db, "ledger", []string{"id", "memo"}, SliceRows(rows), BulkTransaction())
+
+	var bulkErr *BulkError
+	if !errors.As(err, &bulkErr) || bulkErr.Row > 9 || inserted != 0 {
+		t.Fatalf("expected a failure at or before row 9 and no rows, got %v and %d rows", err, inserted)
+	}
+	if last := srv.statements[len(srv.statements)-1]; last != "ROLLBACK" {
+		t.Errorf("expected the transaction to be rolled back, last statement was %q", last)
+	}
+
+	// A row which does not fit into a statement on its own
+	rows[9][1] = strings.Repeat("x", limit)
+	if _, err = BulkInsert(context.Background(), db, "ledger", []string{"id", "memo"}, SliceRows(rows)); !errors.As(err, &bulkErr) || bulkErr.Row != 9 || !errors.Is(err, ErrPktTooLarge) {
+		t.Fatalf("expected ErrPktTooLarge at row 9, got %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+package mdb
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/encoding"
+	// Registers the gzip compressor, usable with compress=gzip
+	_ "google.golang.org/grpc/encoding/gzip"
+)
+
+// RegisterCompressor registers a gRPC compressor. It can then be used by
+// adding compress=<name> to the DSN, where name is c.Name().
+// The server must support the same compressor.
+//
+// RegisterCompressor must be called during initialization, before any
+// connection is opened.
+//
+//  func init() {
+//  	mdb.RegisterCompressor(snappyCompressor{})
+//  }
+//  db, err := sql.Open("mdb", "system:password@tcp(localhost:5461)/main?compress=snappy")
+//
+func RegisterCompressor(c encoding.Compressor) error {
+	name := c.Name()
+	if _, isBool := parseBool(name); isBool || strings.ToLower(name) == "identity" {
+		return fmt.Errorf("compressor name '%s' is reserved", name)
+	}
+
+	encoding.RegisterCompressor(c)
+	return nil
+}
+
+func isRegisteredCompressor(name string) bool {
+	return encoding.GetCompressor(name) != nil
+}
@@ -5,12 +5,16 @@ import (
 	"database/sql/driver"
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/blockpointSystems/protocol-buffers/v1/odbc"
 	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/status"
 	"math"
 	"strconv"
-	"strings"
 	"time"
 )
 
@@ -21,22 +25,30 @@ import (
 type Conn struct {
 	// Managerial
 	cfg    *Config
+	host   *host
 	status statusFlag
+	dbName string // database selected on the session
 
 	// TODO: Make atomic
 	closed uint32
 
 	// Operational
 	odbc.MDBServiceClient
-	auth *odbc.AuthPacket
+	channel *grpc.ClientConn
+	release func() // releases the channel shared with the connector
+	auth    *odbc.AuthPacket
 
 	// Query
 	activeQuery         uint32
 	queryResponseStream *odbc.MDBService_QueryClient
+	cancelStream        context.CancelFunc
+
+	// Statements, nil if the cache is disabled
+	stmtCache *stmtCache
 }
 
 // Handles parameters set in DSN after the connection is established
-func (db *Conn) configureConnection() (err error) {
+func (db *Conn) configureConnection(ctx context.Context) (err error) {
 	// Nothing to really do here, only the basic charset is allowed
 
 	// Resolve the auth packet
@@ -46,17 +58,25 @@ func (db *Conn) configureConnection() (err error) {
 
 	var (
 		initReq = &odbc.InitializationRequest{
-			Username: db.cfg.User,
-			Password: db.cfg.Password,
-			DbName:   db.cfg.DBName,
-			Auth:     db.auth,
+			DbName: db.cfg.DBName,
+			Auth:   db.auth,
 		}
 	)
 
-	db.auth, err = db.MDBServiceClient.InitializeConnection(
-		context.Background(),
-		initReq,
-	)
+	// Resolved on every connection to pick up rotated credentials
+	initReq.Username, initReq.Password, err = db.cfg.credentials(ctx)
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := db.writeContext(ctx)
+	defer cancel()
+
+	db.auth, err = db.MDBServiceClient.InitializeConnection(ctx, initReq)
+	if err != nil {
+		return
+	}
+	db.dbName = db.cfg.DBName
 
 	//var cmdSet strings.Builder
 	//for param, val := range db.cfg.Params {
@@ -100,14 +120,39 @@ func (db *Conn) configureConnection() (err error) {
 	return
 }
 
+// PrepareContext returns a prepared statement, bound to this connection.
+// Preparing does not involve the server, so the context is not used.
+func (db *Conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	return db.Prepare(query)
+}
+
 // Prepare returns a prepared statement, bound to this connection.
 func (db *Conn) Prepare(query string) (s driver.Stmt, err error) {
-	s = &Stmt{
+	if db.IsClosed() {
+		errLog.Print(ErrInvalidConn)
+		return nil, driver.ErrBadConn
+	}
+
+	if stmt := db.stmtCache.get(query); stmt != nil {
+		return stmt, nil
+	}
+
+	stmt := &Stmt{
 		conn:       db,
 		stmt:       query,
-		paramCount: strings.Count(query, "?"),
+		paramCount: countInputs(placeholders(query, db.status&statusNoBackslashEscapes != 0)),
+		refs:       1,
 	}
-	return
+	if db.stmtCache != nil {
+		db.stmtCache.put(stmt)
+	}
+	return stmt, nil
+}
+
+// StmtCacheStats returns the hit and miss counters of the prepared
+// statement cache of the connection.
+func (db *Conn) StmtCacheStats() StmtCacheStats {
+	return db.stmtCache.stats()
 }
 
 // Close invalidates and potentially stops any current
@@ -121,20 +166,124 @@ func (db *Conn) Prepare(query string) (s driver.Stmt, err error) {
 func (db *Conn) Close() (err error) {
 	if !db.IsClosed() {
 		db.SetClosed()
+		db.stmtCache.clear()
+		// database/sql does not retry Close, the channel is released
+		// even if the server can not be reached.
+		defer db.releaseChannel()
+
 		if db.IsActiveQuery() {
 			err = db.closeQuery()
-			if err != nil {
-				db.SetNotClosed()
-				return
-			}
 		}
 
-		_, err = db.MDBServiceClient.Close(context.Background(), db.auth)
+		ctx, cancel := db.writeContext(context.Background())
+		_, closeErr := db.MDBServiceClient.Close(ctx, db.auth)
+		cancel()
 		db.MDBServiceClient = nil
+		if err == nil {
+			err = closeErr
+		}
+	}
+	return
+}
+
+// ResetSession implements driver.SessionResetter. It is called before a
+// connection is reused by database/sql and restores the session to its
+// state when the connection was opened: an orphaned query is closed and the
+// database of the DSN is selected again.
+func (db *Conn) ResetSession(ctx context.Context) (err error) {
+	if db.IsClosed() {
+		return driver.ErrBadConn
+	}
+
+	if err = db.abortQuery(); err != nil {
+		errLog.Print(err)
+		return driver.ErrBadConn
+	}
+
+	if db.cfg.DBName != "" && db.dbName != db.cfg.DBName {
+		if _, _, err = db.exec(ctx, "USE "+db.cfg.DBName); err != nil {
+			errLog.Print(err)
+			return driver.ErrBadConn
+		}
 	}
 	return
 }
 
+// Ping implements driver.Pinger. It makes a lightweight round trip to the
+// server on the session of the connection.
+func (db *Conn) Ping(ctx context.Context) (err error) {
+	if db.IsClosed() {
+		errLog.Print(ErrInvalidConn)
+		return driver.ErrBadConn
+	}
+
+	// Closing the query would abort the rows still being read, settle for
+	// the state of the channel instead.
+	if db.IsActiveQuery() {
+		if !db.isChannelHealthy() {
+			return driver.ErrBadConn
+		}
+		return nil
+	}
+
+	rpcCtx, cancel := db.writeContext(ctx)
+	defer cancel()
+
+	// With no query open CloseQuery is a no-op on the server.
+	err = db.withSession(func() (err error) {
+		_, err = db.MDBServiceClient.CloseQuery(rpcCtx, db.auth)
+		return
+	})
+	if err == driver.ErrBadConn {
+		return
+	}
+
+	switch status.Code(err) {
+	case codes.OK:
+		return nil
+	case codes.Canceled, codes.DeadlineExceeded:
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		// The server did not answer within the write timeout.
+		errLog.Print(err)
+		return driver.ErrBadConn
+	case codes.Unavailable:
+		// CloseQuery is safe to repeat, the connection is discarded
+		// either way.
+		db.markBadConn(err)
+		return driver.ErrBadConn
+	case codes.Unauthenticated:
+		return driver.ErrBadConn
+	}
+
+	// Any other status was sent by the server, so it is alive.
+	return nil
+}
+
+// IsValid implements driver.Validator. It reports whether the connection
+// can be returned to the pool.
+func (db *Conn) IsValid() bool {
+	if db.IsClosed() || db.IsActiveQuery() {
+		return false
+	}
+	return !db.cfg.CheckConnLiveness || db.isChannelHealthy()
+}
+
+// isChannelHealthy reports whether the gRPC channel is usable. An idle
+// channel reconnects on the next RPC.
+func (db *Conn) isChannelHealthy() bool {
+	if db.channel == nil {
+		return false
+	}
+
+	switch db.channel.GetState() {
+	case connectivity.TransientFailure, connectivity.Shutdown:
+		return false
+	}
+	return true
+}
+
 // Begin starts and returns a new transaction.
 //
 // Deprecated: Drivers should implement ConnBeginTx instead (or additionally).
@@ -168,17 +317,26 @@ func (db *Conn) begin(ctx context.Context, xactOpts driver.TxOptions) (xact driv
 		req = &odbc.XactRequest{
 			IsolationLevel: int32(xactOpts.Isolation),
 			ReadOnly:       xactOpts.ReadOnly,
-			Auth:           db.auth,
 		}
 		resp *odbc.XactResponse
 	)
-	resp, err = db.MDBServiceClient.Begin(ctx, req)
+	ctx, cancel := db.writeContext(ctx)
+	defer cancel()
+
+	ready := db.isChannelReady()
+	err = db.withSession(func() (err error) {
+		req.Auth = db.auth
+		resp, err = db.MDBServiceClient.Begin(ctx, req)
+		return
+	})
 	if err != nil {
 		errLog.Print(err)
 		//err = driver.ErrBadConn
-		err = db.markBadConn(err)
+		err = db.markBadConn(notSent(err, ready))
 		return
 	}
+	db.updateAuth(resp.GetAuth())
+	db.status |= statusInTrans
 
 	return CreateTransaction(resp.GetXactId(), DEFAULT_XACT_OPTIONS, db), err
 }
@@ -190,7 +348,23 @@ func (db *Conn) begin(ctx context.Context, xactOpts driver.TxOptions) (xact driv
 // and then close the statement.
 //
 // Exec may return ErrSkip.
+//
+// Deprecated: Drivers should implement ExecerContext instead.
 func (db *Conn) Exec(query string, args []driver.Value) (driver.Result, error) {
+	return db.execArgs(context.Background(), query, args)
+}
+
+// ExecContext executes a query that doesn't return rows. The context is
+// carried into the RPC, so its deadline and metadata reach the server.
+func (db *Conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	query, dargs, err := bindNamed(query, db.status&statusNoBackslashEscapes != 0, args)
+	if err != nil {
+		return nil, err
+	}
+	return db.execArgs(ctx, query, dargs)
+}
+
+func (db *Conn) execArgs(ctx context.Context, query string, args []driver.Value) (driver.Result, error) {
 	var (
 		result Result
 		err    error
@@ -206,8 +380,7 @@ func (db *Conn) Exec(query string, args []driver.Value) (driver.Result, error) {
 	// Interpolate parameters if provided
 	if len(args) != 0 {
 		if !db.cfg.InterpolateParams {
-			err = driver.ErrBadConn
-			return nil, err
+			return nil, ErrInterpolationRequired
 		}
 		// try to interpolate the parameters to save extra roundtrips for preparing and closing a statement
 		prepared, err := db.interpolateParams(query, args)
@@ -217,27 +390,64 @@ func (db *Conn) Exec(query string, args []driver.Value) (driver.Result, error) {
 		query = prepared
 	}
 
-	result.affectedRows, result.insertId, err = db.exec(query)
+	// Run the statements of a script one by one. If one fails, the
+	// results of the statements before it are returned with a *BatchError.
+	if db.cfg.MultiStatements {
+		if statements := splitStatements(query, db.status&statusNoBackslashEscapes != 0); len(statements) > 1 {
+			result.allAffectedRows = make([]int64, 0, len(statements))
+			result.allInsertIds = make([]int64, 0, len(statements))
+			for i, statement := range statements {
+				affectedRows, insertId, err := db.exec(ctx, statement)
+				if err != nil {
+					if i > 0 && errors.Is(err, driver.ErrBadConn) {
+						// database/sql would run the whole script again,
+						// the statements before this one have already run.
+						errLog.Print(err)
+						err = ErrInvalidConn
+					}
+					return &result, &BatchError{Index: i, Err: err}
+				}
+				result.affectedRows, result.insertId = affectedRows, insertId
+				result.allAffectedRows = append(result.allAffectedRows, affectedRows)
+				result.allInsertIds = append(result.allInsertIds, insertId)
+			}
+			return &result, nil
+		}
+	}
+
+	result.affectedRows, result.insertId, err = db.exec(ctx, query)
 	return &result, err
 }
 
 // Internal function to execute commands
-func (db *Conn) exec(query string) (affectedRows, insertId int64, err error) {
+func (db *Conn) exec(ctx context.Context, query string) (affectedRows, insertId int64, err error) {
 	var (
 		req = &odbc.ExecRequest{
-			Auth:      db.auth,
 			Statement: query,
 		}
 
 		resp *odbc.ExecResponse
 	)
 
+	ctx, cancel := db.writeContext(ctx)
+	defer cancel()
+
 	// Send the command
-	resp, err = db.MDBServiceClient.Exec(context.Background(), req)
+	ready := db.isChannelReady()
+	err = db.withSession(func() (err error) {
+		req.Auth = db.auth
+		resp, err = db.MDBServiceClient.Exec(ctx, req, db.compressOptions(len(query))...)
+		return
+	})
 	if err != nil {
+		err = db.markBadConn(notSent(err, ready))
 		return
 	}
-	// TODO: Update JWT
+	db.updateAuth(resp.GetAuth())
+
+	if dbName, isUse := parseUseStatement(query); isUse {
+		db.dbName = dbName
+	}
 
 	// Log affected Rows
 	affectedRows = resp.AffectedRows
@@ -247,99 +457,190 @@ func (db *Conn) exec(query string) (affectedRows, insertId int64, err error) {
 	return
 }
 
+// Query executes a query that may return rows, such as a SELECT.
+//
+// Deprecated: Drivers should implement QueryerContext instead.
 func (db *Conn) Query(query string, args []driver.Value) (driver.Rows, error) {
-	return db.query(query, args)
+	return db.query(context.Background(), query, args)
 }
 
-func (db *Conn) query(query string, args []driver.Value) (*Rows, error) {
-	var (
-		ctx = context.Background()
-
-		req        *odbc.QueryRequest
-		respClient odbc.MDBService_QueryClient
-
-		queryResp *odbc.QueryResponse
+// QueryContext executes a query that may return rows. The context is
+// carried into the query stream, so its deadline and metadata reach the
+// server.
+func (db *Conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	query, dargs, err := bindNamed(query, db.status&statusNoBackslashEscapes != 0, args)
+	if err != nil {
+		return nil, err
+	}
+	return db.query(ctx, query, dargs)
+}
 
-		err error
-	)
+func (db *Conn) query(ctx context.Context, query string, args []driver.Value) (*Rows, error) {
+	var err error
 
 	if db.IsClosed() {
 		errLog.Print(ErrInvalidConn)
 		return nil, driver.ErrBadConn
 	}
 
-	// Lock, check activeQuery flag, if not active, update, and unlock; set activeQuery to true.
 	if db.IsActiveQuery() {
 		return &Rows{}, fmt.Errorf("query already active")
 	}
-	db.SetActiveQuery()
 
 	if len(args) != 0 {
 		if !db.cfg.InterpolateParams {
-			return nil, driver.ErrSkip
+			return nil, ErrInterpolationRequired
 		}
 
 		query, err = db.interpolateParams(query, args)
 		if err != nil {
-			db.SetNotActiveQuery()
 			return nil, db.markBadConn(err)
 		}
 	}
 
-	req = &odbc.QueryRequest{
-		Auth:              db.auth,
-		Statement:         query,
-		MaxResponseLength: db.cfg.MaxRowCount,
-		BatchSize:         db.cfg.FetchSize,
+	// The statements of a script are run one at a time, each of them is
+	// a result set of the rows.
+	var statements []string
+	if db.cfg.MultiStatements {
+		statements = splitStatements(query, db.status&statusNoBackslashEscapes != 0)
 	}
-
-	// Send command
-	respClient, err = db.MDBServiceClient.Query(ctx, req)
-	if err != nil {
-		db.SetNotActiveQuery()
-		return nil, db.markBadConn(err)
+	if len(statements) == 0 {
+		statements = []string{query}
 	}
 
-	// Store the stream in the connection object
-	//Now stored in the rows directly
-	db.queryResponseStream = &respClient
+	var resp = &Rows{
+		ctx:     ctx,
+		set:     resultSet{cfg: db.cfg},
+		pending: statements[1:],
+		open: func(statement string) (func() (*odbc.QueryResponse, error), *odbc.QueryResponse, error) {
+			return db.openQuery(ctx, statement)
+		},
+		close: db.abortQuery,
+	}
 
-	// Grab the first result set
-	queryResp, err = respClient.Recv()
-	if err != nil {
-		db.SetNotActiveQuery()
-		return &Rows{}, err
+	if err = resp.start(statements[0]); err != nil {
+		return nil, err
 	}
+	return resp, nil
+}
 
-	// Deserialize the response and build the rows
-	var resp = &Rows{
-		streamRecv: &respClient,
-		schema:     queryResp.GetRespSchema(),
-		set:        buildResultSet(queryResp.GetRespSchema(), queryResp.GetResultSet()),
-		done:       queryResp.GetDone(),
+// openQuery sends a query and receives its first batch of rows. The
+// returned function receives the following batches.
+func (db *Conn) openQuery(ctx context.Context, query string) (recv func() (*odbc.QueryResponse, error), queryResp *odbc.QueryResponse, err error) {
+	// Lock, check activeQuery flag, if not active, update, and unlock; set activeQuery to true.
+	if db.IsActiveQuery() {
+		return nil, nil, fmt.Errorf("query already active")
 	}
+	db.SetActiveQuery()
 
-	resp.close = func() (err error) {
-		if db.IsActiveQuery() {
-			err = db.closeQuery()
-			if err != nil {
-				return err
+	var (
+		req = &odbc.QueryRequest{
+			Auth:              db.auth,
+			Statement:         query,
+			MaxResponseLength: db.cfg.MaxRowCount,
+			BatchSize:         db.cfg.FetchSize,
+		}
+		respClient   odbc.MDBService_QueryClient
+		cancelStream context.CancelFunc
+	)
+
+	ready := db.isChannelReady()
+	err = db.withSession(func() (err error) {
+		req.Auth = db.auth
+
+		// The stream lives until the rows are closed, cancelling it aborts
+		// any pending send or receive.
+		var streamCtx context.Context
+		streamCtx, cancelStream = context.WithCancel(ctx)
+
+		// Send command
+		err = withStreamTimeout(db.cfg.WriteTimeout, cancelStream, ErrWriteTimeout, func() (err error) {
+			// The server compresses its responses with the compressor of
+			// the request, so the request is compressed regardless of size.
+			respClient, err = db.MDBServiceClient.Query(streamCtx, req, db.compressOptions(db.cfg.CompressThreshold)...)
+			return
+		})
+		if err != nil {
+			cancelStream()
+			return
+		}
+
+		// Store the stream in the connection object
+		db.queryResponseStream = &respClient
+		db.cancelStream = cancelStream
+
+		recv = func() (queryResp *odbc.QueryResponse, err error) {
+			err = withStreamTimeout(db.cfg.ReadTimeout, cancelStream, ErrReadTimeout, func() (err error) {
+				queryResp, err = respClient.Recv()
+				return
+			})
+			if err == nil {
+				db.updateAuth(queryResp.GetAuth())
+				return
 			}
+			if ctx.Err() != nil {
+				// The caller gave up on the rows, stop the query on the
+				// server so the connection can be reused.
+				db.abortQuery()
+				err = ctx.Err()
+			}
+			return
 		}
 
+		// Grab the first result set
+		queryResp, err = recv()
+		if err != nil {
+			cancelStream()
+			if err == ErrReadTimeout {
+				// The server is still working on the query, stop it.
+				db.closeQuery()
+			}
+		}
+		return
+	})
+	if err != nil {
+		db.queryResponseStream = nil
+		db.cancelStream = nil
 		db.SetNotActiveQuery()
-		return (*db.queryResponseStream).CloseSend()
+		if ctx.Err() != nil {
+			return nil, nil, ctx.Err()
+		}
+		return nil, nil, db.markBadConn(notSent(err, ready))
+	}
+	return
+}
+
+// abortQuery tears down the stream of the open query and closes the query
+// on the server.
+func (db *Conn) abortQuery() (err error) {
+	if db.cancelStream != nil {
+		db.cancelStream()
+		db.cancelStream = nil
 	}
+	db.queryResponseStream = nil
 
-	return resp, nil
+	if db.IsActiveQuery() {
+		err = db.closeQuery()
+		if err != nil {
+			return
+		}
+	}
+
+	db.SetNotActiveQuery()
+	return
 }
 
 func (db *Conn) closeQuery() (err error) {
-	_, err = db.CloseQuery(context.Background(), db.auth)
+	ctx, cancel := db.writeContext(context.Background())
+	defer cancel()
+
+	_, err = db.CloseQuery(ctx, db.auth)
 	return
 }
 
-func buildResultSet(schema *odbc.Schema, set []*odbc.Row) (rs resultSet) {
+func buildResultSet(cfg *Config, schema *odbc.Schema, set []*odbc.Row) (rs resultSet) {
+	rs.cfg = cfg
+
 	if schema.GetTableName() == "" {
 		rs.columnNames = schema.GetColumnName()
 	} else {
@@ -353,7 +654,9 @@ func buildResultSet(schema *odbc.Schema, set []*odbc.Row) (rs resultSet) {
 	return
 }
 
-func convertColumnToValue(col []byte, datatype odbc.Datatype) driver.Value {
+// convertColumnToValue decodes a column. cfg may be nil, in which case the
+// defaults of NewConfig apply.
+func convertColumnToValue(col []byte, datatype odbc.Datatype, cfg *Config) driver.Value {
 	// TODO: Test the int / uint cases
 
 	//if len(col) == 0 {
@@ -381,7 +684,11 @@ func convertColumnToValue(col []byte, datatype odbc.Datatype) driver.Value {
 	case odbc.Datatype_INT64:
 		return int64(binary.LittleEndian.Uint64(col))
 	case odbc.Datatype_UINT64:
-		return int64(binary.LittleEndian.Uint64(col))
+		if cfg != nil && cfg.Uint64AsString {
+			return strconv.FormatUint(binary.LittleEndian.Uint64(col), 10)
+		}
+		// database/sql converts uint64 losslessly when scanning
+		return binary.LittleEndian.Uint64(col)
 	case odbc.Datatype_FLOAT32:
 		bits := binary.LittleEndian.Uint32(col)
 		return float64(math.Float32frombits(bits))
@@ -390,32 +697,8 @@ func convertColumnToValue(col []byte, datatype odbc.Datatype) driver.Value {
 		return math.Float64frombits(bits)
 	case odbc.Datatype_BOOL:
 		return bool(int8(col[0]) == 1)
-	case odbc.Datatype_TIMESTAMP:
-		return time.Unix(0, int64(binary.LittleEndian.Uint64(col[0:8])))
-	case odbc.Datatype_DATETIME:
-		return time.Unix(int64(binary.LittleEndian.Uint64(col[0:8])), 0)
-	case odbc.Datatype_DATE:
-		return time.Date(
-			int(binary.LittleEndian.Uint16(col[:2])),
-			time.Month(int(col[2])),
-			int(col[3]),
-			0,
-			0,
-			0,
-			0,
-			time.UTC,
-		)
-	case odbc.Datatype_TIME:
-		return time.Date(
-			0,
-			0,
-			0,
-			int(col[0]),
-			int(col[1]),
-			int(col[2]),
-			0,
-			time.UTC,
-		)
+	case odbc.Datatype_TIMESTAMP, odbc.Datatype_DATETIME, odbc.Datatype_DATE, odbc.Datatype_TIME:
+		return convertTemporal(col, datatype, cfg)
 	case odbc.Datatype_UUID:
 		uuid, _ := uuid.FromBytes(col)
 		return uuid.String()
@@ -423,103 +706,145 @@ func convertColumnToValue(col []byte, datatype odbc.Datatype) driver.Value {
 	return nil
 }
 
+// convertTemporal decodes a temporal column in the location of cfg.
+// TIMESTAMP and DATETIME are instants, nanoseconds and seconds since the
+// epoch. DATE and TIME are wall clock values, a TIME is a time of day on
+// January 1 of year 0. With TemporalAsText the canonical text of the value
+// is returned.
+func convertTemporal(col []byte, datatype odbc.Datatype, cfg *Config) driver.Value {
+	var (
+		loc    = time.UTC
+		asText bool
+	)
+	if cfg != nil {
+		loc, asText = cfg.Loc, cfg.TemporalAsText
+	}
+
+	var (
+		t      time.Time
+		layout string
+	)
+	switch datatype {
+	case odbc.Datatype_TIMESTAMP:
+		t = time.Unix(0, int64(binary.LittleEndian.Uint64(col[0:8]))).In(loc)
+		layout = timestampFormat
+	case odbc.Datatype_DATETIME:
+		t = time.Unix(int64(binary.LittleEndian.Uint64(col[0:8])), 0).In(loc)
+		layout = datetimeFormat
+	case odbc.Datatype_DATE:
+		t = time.Date(int(binary.LittleEndian.Uint16(col[:2])), time.Month(col[2]), int(col[3]), 0, 0, 0, 0, loc)
+		layout = dateFormat
+	case odbc.Datatype_TIME:
+		t = time.Date(0, time.January, 1, int(col[0]), int(col[1]), int(col[2]), 0, loc)
+		layout = timeOfDayFormat
+	}
+
+	if asText {
+		return t.Format(layout)
+	}
+	return t
+}
+
 func (db *Conn) interpolateParams(query string, args []driver.Value) (resp string, err error) {
 	// Number of ? should be same to len(args)
-	if strings.Count(query, "?") != len(args) {
-		err = driver.ErrSkip
+	found := placeholders(query, db.status&statusNoBackslashEscapes != 0)
+	if len(found) != len(args) {
+		err = fmt.Errorf("%w: the query has %d, got %d arguments", ErrPlaceholderCount, len(found), len(args))
 		return
 	}
 
 	// Initialize the buffer
 	var (
-		buf    = make([]byte, 0, len(query))
-		argPos int
+		buf  = make([]byte, 0, len(query))
+		last int
 	)
 
-	for i := 0; i < len(query); i++ {
-		q := strings.IndexByte(query[i:], '?')
-		if q == -1 {
-			buf = append(buf, query[i:]...)
-			break
+	for argPos, p := range found {
+		if p.name != "" {
+			return "", fmt.Errorf("mdb: placeholder %s requires a named argument", query[p.pos:p.end])
 		}
-		buf = append(buf, query[i:i+q]...)
-		i += q
+		buf = append(buf, query[last:p.pos]...)
+		last = p.end
 
-		arg := args[argPos]
-		argPos++
+		buf, err = db.appendValue(buf, args[argPos])
+		if err != nil {
+			return "", err
+		}
 
-		if arg == nil {
-			buf = append(buf, "NULL"...)
-			continue
+		if len(buf) > db.GetMaxPacketSize() {
+			return "", ErrPktTooLarge
 		}
+	}
+	buf = append(buf, query[last:]...)
+	return string(buf), nil
+}
 
-		switch v := arg.(type) {
-		case int64:
-			buf = strconv.AppendInt(buf, v, 10)
-		case uint64:
-			// Handle uint64 explicitly because our custom ConvertValue emits unsigned values
-			buf = strconv.AppendUint(buf, v, 10)
-		case float64:
-			buf = strconv.AppendFloat(buf, v, 'g', -1, 64)
-		case bool:
-			if v {
-				buf = append(buf, '1')
-			} else {
-				buf = append(buf, '0')
-			}
-		case time.Time:
-			if v.IsZero() {
-				buf = append(buf, "'0000-00-00'"...)
-			} else {
-				buf = append(buf, '"')
-				buf, err = appendDateTime(buf, v.In(db.cfg.Loc))
-				if err != nil {
-					return "", err
-				}
-				buf = append(buf, '"')
-			}
-		case json.RawMessage:
-			buf = append(buf, '"')
-			if db.status&statusNoBackslashEscapes == 0 {
-				buf = escapeBytesBackslash(buf, v)
-			} else {
-				buf = escapeBytesQuotes(buf, v)
-			}
-			buf = append(buf, '"')
-		case []byte:
-			if v == nil {
-				buf = append(buf, "NULL"...)
-			} else {
-				//buf = append(buf, "_binary'"...)
-				//if db.status&statusNoBackslashEscapes == 0 {
-				//	buf = escapeBytesBackslash(buf, v)
-				//} else {
-				//	buf = escapeBytesQuotes(buf, v)
-				//}
-				//buf = append(buf, '"')
-				buf = append(
-					buf,
-					fmt.Sprintf("%d", v)...,
-				)
-			}
-		case string:
+// appendValue appends the bSQL literal of a value to buf.
+func (db *Conn) appendValue(buf []byte, arg driver.Value) (_ []byte, err error) {
+	if arg == nil {
+		return append(buf, "NULL"...), nil
+	}
+
+	switch v := arg.(type) {
+	case int64:
+		buf = strconv.AppendInt(buf, v, 10)
+	case uint64:
+		// Handle uint64 explicitly because our custom ConvertValue emits unsigned values
+		buf = strconv.AppendUint(buf, v, 10)
+	case float32:
+		buf = strconv.AppendFloat(buf, float64(v), 'g', -1, 32)
+	case float64:
+		buf = strconv.AppendFloat(buf, v, 'g', -1, 64)
+	case bool:
+		if v {
+			buf = append(buf, '1')
+		} else {
+			buf = append(buf, '0')
+		}
+	case time.Time:
+		if v.IsZero() {
+			// bSQL has no zero date, the zero time stands for no value
+			buf = append(buf, "NULL"...)
+		} else if isTimeOfDay(v) {
+			buf = appendTimeOfDay(buf, v.In(db.cfg.Loc))
+		} else {
 			buf = append(buf, '"')
-			if db.status&statusNoBackslashEscapes == 0 {
-				buf = escapeStringBackslash(buf, v)
-			} else {
-				buf = escapeStringQuotes(buf, v)
+			buf, err = appendDateTime(buf, v.In(db.cfg.Loc))
+			if err != nil {
+				return nil, err
 			}
 			buf = append(buf, '"')
-		default:
-			return "", driver.ErrSkip
 		}
-
-		if len(buf) > db.GetMaxPacketSize() {
-			return "", driver.ErrSkip
+	case timeOfDayValue:
+		buf = appendTimeOfDay(buf, time.Time(v).In(db.cfg.Loc))
+	case json.RawMessage:
+		buf = append(buf, '"')
+		if db.status&statusNoBackslashEscapes == 0 {
+			buf = escapeBytesBackslash(buf, v)
+		} else {
+			buf = escapeBytesQuotes(buf, v)
 		}
+		buf = append(buf, '"')
+	case []byte:
+		if v == nil {
+			buf = append(buf, "NULL"...)
+		} else {
+			buf = appendByteArray(buf, v)
+		}
+	case string:
+		buf = append(buf, '"')
+		if db.status&statusNoBackslashEscapes == 0 {
+			buf = escapeStringBackslash(buf, v)
+		} else {
+			buf = escapeStringQuotes(buf, v)
+		}
+		buf = append(buf, '"')
+	case uuid.UUID:
+		buf = append(buf, '"')
+		buf = append(buf, v.String()...)
+		buf = append(buf, '"')
+	default:
+		return nil, fmt.Errorf("mdb: unsupported argument type %T", v)
 	}
-	if argPos != len(args) {
-		return "", driver.ErrSkip
-	}
-	return string(buf), nil
+	return buf, nil
 }
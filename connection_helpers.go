@@ -1,8 +1,16 @@
 package mdb
 
 import (
+	"context"
 	"database/sql/driver"
 	"sync/atomic"
+	"time"
+
+	"github.com/blockpointSystems/protocol-buffers/v1/odbc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/status"
 )
 
 func (db *Conn) IsClosed() bool {
@@ -29,12 +37,123 @@ func (db *Conn) SetNotActiveQuery() {
 	atomic.StoreUint32(&db.activeQuery, 0)
 }
 
+// markBadConn takes the host out of rotation if the server is gone. Only
+// if the request was not sent, database/sql is told to retry it on a new
+// connection, a request which may have reached the server must not be
+// replayed.
 func (db *Conn) markBadConn(err error) error {
 	if db == nil {
 		return err
 	}
-	if err != errBadConnNoWrite {
-		return err
+	switch {
+	case err == errBadConnNoWrite:
+		db.host.markDown()
+		return driver.ErrBadConn
+	case status.Code(err) == codes.Unavailable:
+		db.host.markDown()
+	}
+	return err
+}
+
+// isChannelReady reports whether the gRPC channel is connected.
+func (db *Conn) isChannelReady() bool {
+	return db.channel != nil && db.channel.GetState() == connectivity.Ready
+}
+
+// notSent replaces an Unavailable error of an RPC with errBadConnNoWrite
+// if the channel was not ready when the RPC was made. gRPC fails such an
+// RPC before the request is sent.
+func notSent(err error, ready bool) error {
+	if !ready && status.Code(err) == codes.Unavailable {
+		return errBadConnNoWrite
+	}
+	return err
+}
+
+// releaseChannel hands the gRPC channel back to the connector.
+func (db *Conn) releaseChannel() {
+	if db.release != nil {
+		db.release()
+		db.release = nil
 	}
-	return driver.ErrBadConn
+}
+
+// compressOptions returns the call options compressing a request carrying
+// a statement of the given size.
+func (db *Conn) compressOptions(size int) []grpc.CallOption {
+	if db.cfg.Compress == "" || size < db.cfg.CompressThreshold {
+		return nil
+	}
+	return []grpc.CallOption{grpc.UseCompressor(db.cfg.Compress)}
+}
+
+// writeContext derives the context for a unary RPC, bounded by the
+// configured write timeout.
+func (db *Conn) writeContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if db.cfg.WriteTimeout > 0 {
+		return context.WithTimeout(ctx, db.cfg.WriteTimeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// withStreamTimeout runs a single send or receive on a stream, cancelling
+// the stream and returning timeoutErr if it does not complete in time.
+func withStreamTimeout(timeout time.Duration, cancel context.CancelFunc, timeoutErr error, fn func() error) error {
+	if timeout <= 0 {
+		return fn()
+	}
+
+	var expired uint32
+	timer := time.AfterFunc(timeout, func() {
+		atomic.StoreUint32(&expired, 1)
+		cancel()
+	})
+
+	err := fn()
+	if !timer.Stop() && atomic.LoadUint32(&expired) != 0 && err != nil {
+		return timeoutErr
+	}
+	return err
+}
+
+// updateAuth adopts a refreshed auth packet sent along with a response.
+func (db *Conn) updateAuth(auth *odbc.AuthPacket) {
+	if auth.GetJWT() != "" {
+		db.auth = auth
+	}
+}
+
+// withSession runs the RPC in fn, which must read db.auth when building its
+// request. If the server reports that the session has expired, a new
+// session is established and fn is retried once. An open transaction is
+// lost with the session, in that case driver.ErrBadConn is returned so the
+// connection is discarded.
+func (db *Conn) withSession(fn func() error) (err error) {
+	err = fn()
+	if status.Code(err) != codes.Unauthenticated {
+		return
+	}
+
+	errLog.Print(err)
+	if db.status&statusInTrans != 0 {
+		return driver.ErrBadConn
+	}
+
+	// Start over with a fresh auth packet
+	dbName := db.dbName
+	db.auth = nil
+	if err = db.configureConnection(context.Background()); err != nil {
+		errLog.Print(err)
+		return driver.ErrBadConn
+	}
+
+	// The new session starts out in the database of the DSN
+	if dbName != db.dbName {
+		if _, _, err = db.exec(context.Background(), "USE "+dbName); err != nil {
+			errLog.Print(err)
+			return driver.ErrBadConn
+		}
+	}
+
+	return fn()
 }
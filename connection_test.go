@@ -0,0 +1,657 @@
+package mdb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blockpointSystems/protocol-buffers/v1/odbc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// connectTo opens a connection to the fake server at addr.
+func connectTo(t *testing.T, addr, params string) *Conn {
+	cfg, err := ParseDSN("system:biglove@tcp(" + addr + ")/master?timeout=5s" + params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := newConnector(cfg).Connect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn.(*Conn)
+}
+
+func TestPingAndIsValid(t *testing.T) {
+	addr, stop := startServer(t, &fakeServer{})
+	conn := connectTo(t, addr, "&writeTimeout=5s")
+	defer conn.Close()
+
+	if err := conn.Ping(context.Background()); err != nil {
+		t.Fatalf("expected ping to succeed, got %v", err)
+	}
+	if !conn.IsValid() {
+		t.Fatal("expected connection to be valid")
+	}
+
+	stop()
+
+	if err := conn.Ping(context.Background()); err != driver.ErrBadConn {
+		t.Fatalf("expected driver.ErrBadConn once the server is gone, got %v", err)
+	}
+}
+
+func TestUnavailableNotReplayed(t *testing.T) {
+	srv := &fakeServer{
+		exec: func(req *odbc.ExecRequest) (*odbc.ExecResponse, error) {
+			return nil, status.Error(codes.Unavailable, "server shutting down")
+		},
+	}
+	addr, stop := startServer(t, srv)
+	defer stop()
+
+	db, err := sql.Open("mdb", "system:biglove@tcp("+addr+")/master?timeout=5s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// The request reached the server, database/sql must not resend it
+	if _, err = db.Exec("INSERT INTO ledger VALUES (1)"); status.Code(err) != codes.Unavailable {
+		t.Fatalf("expected Unavailable, got %v", err)
+	}
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if len(srv.statements) != 1 {
+		t.Fatalf("expected the statement to be sent once, got %q", srv.statements)
+	}
+}
+
+func TestUnavailableNotSent(t *testing.T) {
+	addr, stop := startServer(t, &fakeServer{})
+	conn := connectTo(t, addr, "")
+	defer conn.Close()
+
+	stop()
+	for deadline := time.Now().Add(5 * time.Second); conn.isChannelReady(); time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the channel to lose its connection")
+		}
+	}
+
+	// Nothing was sent, database/sql may retry on another connection
+	if _, err := conn.ExecContext(context.Background(), "INSERT INTO ledger VALUES (1)", nil); err != driver.ErrBadConn {
+		t.Fatalf("expected driver.ErrBadConn, got %v", err)
+	}
+	if !conn.host.isDown(time.Now()) {
+		t.Error("expected the host to be marked down")
+	}
+}
+
+func TestSessionRefresh(t *testing.T) {
+	var (
+		srv = &fakeServer{}
+
+		expired = "token-1-refreshed"
+	)
+	srv.exec = func(req *odbc.ExecRequest) (*odbc.ExecResponse, error) {
+		switch req.GetAuth().GetJWT() {
+		case "token-1":
+			// Refresh the token along with the response
+			return &odbc.ExecResponse{Auth: &odbc.AuthPacket{LoginId: 1, JWT: expired}}, nil
+		case expired:
+			return nil, status.Error(codes.Unauthenticated, "session expired")
+		}
+		return &odbc.ExecResponse{AffectedRows: 1}, nil
+	}
+
+	addr, stop := startServer(t, srv)
+	defer stop()
+
+	conn := connectTo(t, addr, "")
+	defer conn.Close()
+
+	if _, _, err := conn.exec(context.Background(), "USE master"); err != nil {
+		t.Fatal(err)
+	}
+	if conn.auth.GetJWT() != expired {
+		t.Fatalf("expected the refreshed token to be adopted, got %q", conn.auth.GetJWT())
+	}
+
+	// The expired session is re-established transparently
+	affectedRows, _, err := conn.exec(context.Background(), "USE master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if affectedRows != 1 || conn.auth.GetJWT() != "token-2" {
+		t.Fatalf("expected the statement to be retried on a new session, got %d rows with %q", affectedRows, conn.auth.GetJWT())
+	}
+
+	// Within a transaction the session can not be replaced
+	if _, err = conn.Begin(); err != nil {
+		t.Fatal(err)
+	}
+	conn.auth = &odbc.AuthPacket{JWT: expired}
+	if _, _, err = conn.exec(context.Background(), "USE master"); err != driver.ErrBadConn {
+		t.Fatalf("expected driver.ErrBadConn within a transaction, got %v", err)
+	}
+}
+
+func TestResetSession(t *testing.T) {
+	srv := &fakeServer{}
+	addr, stop := startServer(t, srv)
+	defer stop()
+
+	conn := connectTo(t, addr, "")
+	defer conn.Close()
+
+	if _, _, err := conn.exec(context.Background(), "USE users"); err != nil {
+		t.Fatal(err)
+	}
+	// An orphaned query left behind by the previous user
+	conn.SetActiveQuery()
+
+	if err := conn.ResetSession(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if conn.IsActiveQuery() || srv.closedQueryCount() != 1 {
+		t.Errorf("expected the orphaned query to be closed, %d CloseQuery calls", srv.closedQueryCount())
+	}
+	if last := srv.statements[len(srv.statements)-1]; last != "USE master" {
+		t.Errorf("expected the database to be restored, last statement was %q", last)
+	}
+
+	// A session already in the right database needs no round trip
+	statements := len(srv.statements)
+	if err := conn.ResetSession(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(srv.statements) != statements {
+		t.Errorf("unexpected statement %q", srv.statements[statements])
+	}
+}
+
+// streamRows sends one row per batch until the stream is cancelled.
+func streamRows(req *odbc.QueryRequest, stream odbc.MDBService_QueryServer) error {
+	schema := &odbc.Schema{
+		ColumnName: []string{"n"},
+		ColumnType: []odbc.Datatype{odbc.Datatype_UINT8},
+	}
+	for n := byte(0); ; n++ {
+		err := stream.Send(&odbc.QueryResponse{
+			RespSchema: schema,
+			ResultSet:  []*odbc.Row{{Columns: [][]byte{{n}}, NullColumnBitmap: []byte{0}}},
+		})
+		if err != nil {
+			return err
+		}
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestQueryCancel(t *testing.T) {
+	srv := &fakeServer{query: streamRows}
+	addr, stop := startServer(t, srv)
+	defer stop()
+
+	conn := connectTo(t, addr, "")
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rows, err := conn.QueryContext(ctx, "SELECT n FROM numbers", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dest := make([]driver.Value, 1)
+	for i := 0; i < 3; i++ {
+		if err = rows.Next(dest); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cancel()
+	if err = rows.Next(dest); err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	rows.Close()
+
+	if conn.IsActiveQuery() || srv.closedQueryCount() != 1 {
+		t.Fatalf("expected the query to be closed once, active=%v closed=%d", conn.IsActiveQuery(), srv.closedQueryCount())
+	}
+	if !conn.IsValid() {
+		t.Fatal("expected the connection to stay valid")
+	}
+	if _, err = conn.ExecContext(context.Background(), "DELETE FROM numbers", nil); err != nil {
+		t.Fatalf("expected the connection to be reusable, got %v", err)
+	}
+}
+
+func TestReadTimeout(t *testing.T) {
+	srv := &fakeServer{
+		// The first batch is sent, then the server stalls
+		query: func(req *odbc.QueryRequest, stream odbc.MDBService_QueryServer) error {
+			err := stream.Send(&odbc.QueryResponse{
+				RespSchema: &odbc.Schema{
+					ColumnName: []string{"n"},
+					ColumnType: []odbc.Datatype{odbc.Datatype_UINT8},
+				},
+				ResultSet: []*odbc.Row{{Columns: [][]byte{{1}}, NullColumnBitmap: []byte{0}}},
+			})
+			if err != nil {
+				return err
+			}
+			<-stream.Context().Done()
+			return stream.Context().Err()
+		},
+	}
+	addr, stop := startServer(t, srv)
+	defer stop()
+
+	conn := connectTo(t, addr, "&readTimeout=100ms")
+	defer conn.Close()
+
+	rows, err := conn.QueryContext(context.Background(), "SELECT n FROM numbers", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dest := make([]driver.Value, 1)
+	if err = rows.Next(dest); err != nil {
+		t.Fatal(err)
+	}
+	if err = rows.Next(dest); err != ErrReadTimeout {
+		t.Fatalf("expected ErrReadTimeout, got %v", err)
+	}
+	rows.Close()
+
+	if conn.IsActiveQuery() || srv.closedQueryCount() != 1 {
+		t.Fatalf("expected the query to be closed once, active=%v closed=%d", conn.IsActiveQuery(), srv.closedQueryCount())
+	}
+}
+
+func TestWriteTimeout(t *testing.T) {
+	srv := &fakeServer{
+		exec: func(req *odbc.ExecRequest) (*odbc.ExecResponse, error) {
+			time.Sleep(time.Second)
+			return &odbc.ExecResponse{}, nil
+		},
+	}
+	addr, stop := startServer(t, srv)
+	defer stop()
+
+	conn := connectTo(t, addr, "&writeTimeout=100ms")
+	defer conn.Close()
+
+	start := time.Now()
+	if _, err := conn.ExecContext(context.Background(), "DELETE FROM numbers", nil); status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("expected the deadline to be exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("expected Exec to give up after 100ms, took %v", elapsed)
+	}
+}
+
+func TestQueryCancelBeforeFirstBatch(t *testing.T) {
+	srv := &fakeServer{
+		query: func(req *odbc.QueryRequest, stream odbc.MDBService_QueryServer) error {
+			<-stream.Context().Done()
+			return stream.Context().Err()
+		},
+	}
+	addr, stop := startServer(t, srv)
+	defer stop()
+
+	conn := connectTo(t, addr, "")
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := conn.QueryContext(ctx, "SELECT n FROM numbers", nil); err != context.DeadlineExceeded {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if conn.IsActiveQuery() || srv.closedQueryCount() != 1 {
+		t.Fatalf("expected the query to be closed once, active=%v closed=%d", conn.IsActiveQuery(), srv.closedQueryCount())
+	}
+	if !conn.IsValid() {
+		t.Fatal("expected the connection to stay valid")
+	}
+}
+
+func TestArgsRequireInterpolation(t *testing.T) {
+	addr, stop := startServer(t, &fakeServer{})
+	defer stop()
+
+	conn := connectTo(t, addr, "")
+	defer conn.Close()
+
+	args := []driver.NamedValue{{Ordinal: 1, Value: int64(5)}}
+	if _, err := conn.ExecContext(context.Background(), "DELETE FROM t WHERE id = ?", args); err != ErrInterpolationRequired {
+		t.Fatalf("expected ErrInterpolationRequired, got %v", err)
+	}
+	if _, err := conn.QueryContext(context.Background(), "SELECT * FROM t WHERE id = ?", args); err != ErrInterpolationRequired {
+		t.Fatalf("expected ErrInterpolationRequired, got %v", err)
+	}
+	if !conn.IsValid() {
+		t.Fatal("expected the connection to stay valid")
+	}
+}
+
+func TestMultiStatements(t *testing.T) {
+	srv := &fakeServer{
+		exec: func(req *odbc.ExecRequest) (*odbc.ExecResponse, error) {
+			if req.GetStatement() == "FAIL" {
+				return nil, errors.New("no such table")
+			}
+			return &odbc.ExecResponse{AffectedRows: int64(len(req.GetStatement()))}, nil
+		},
+		// SELECT n returns a single row holding n
+		query: func(req *odbc.QueryRequest, stream odbc.MDBService_QueryServer) error {
+			n, err := strconv.Atoi(strings.TrimPrefix(req.GetStatement(), "SELECT "))
+			if err != nil {
+				return err
+			}
+			return stream.Send(&odbc.QueryResponse{
+				RespSchema: &odbc.Schema{
+					ColumnName: []string{"n"},
+					ColumnType: []odbc.Datatype{odbc.Datatype_UINT8},
+				},
+				ResultSet: []*odbc.Row{{Columns: [][]byte{{byte(n)}}, NullColumnBitmap: []byte{0}}},
+				Done:      true,
+			})
+		},
+	}
+	addr, stop := startServer(t, srv)
+	defer stop()
+
+	conn := connectTo(t, addr, "&multiStatements=true")
+	defer conn.Close()
+
+	res, err := conn.ExecContext(context.Background(), "DELETE FROM a; DELETE FROM bb", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := res.(*Result).AllRowsAffected(); !reflect.DeepEqual(got, []int64{13, 14}) {
+		t.Errorf("expected affected rows [13 14], got %v", got)
+	}
+
+	res, err = conn.ExecContext(context.Background(), "DELETE FROM a; FAIL; DELETE FROM bb", nil)
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || batchErr.Index != 1 {
+		t.Fatalf("expected the second statement to fail, got %v", err)
+	}
+	if got := res.(*Result).AllRowsAffected(); !reflect.DeepEqual(got, []int64{13}) {
+		t.Errorf("expected affected rows [13], got %v", got)
+	}
+
+	res, err = conn.ExecContext(context.Background(), "FAIL; DELETE FROM a", nil)
+	if !errors.As(err, &batchErr) || batchErr.Index != 0 {
+		t.Fatalf("expected the first statement to fail, got %v", err)
+	}
+	if got := res.(*Result).AllRowsAffected(); len(got) != 0 {
+		t.Errorf("expected no affected rows, got %v", got)
+	}
+
+	rows, err := conn.QueryContext(context.Background(), "SELECT 1; SELECT 2", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var (
+		mr   = rows.(driver.RowsNextResultSet)
+		dest = make([]driver.Value, 1)
+		got  []driver.Value
+	)
+	for {
+		for mr.Next(dest) == nil {
+			got = append(got, dest[0])
+		}
+		if !mr.HasNextResultSet() {
+			break
+		}
+		if err = mr.NextResultSet(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want := []driver.Value{int64(1), int64(2)}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestMultiStatementsNotReplayed(t *testing.T) {
+	srv := &fakeServer{
+		exec: func(req *odbc.ExecRequest) (*odbc.ExecResponse, error) {
+			switch req.GetStatement() {
+			case "B":
+				return nil, status.Error(codes.Unavailable, "server shutting down")
+			case "D":
+				return nil, status.Error(codes.Unauthenticated, "session expired")
+			}
+			return &odbc.ExecResponse{AffectedRows: 1}, nil
+		},
+	}
+	addr, stop := startServer(t, srv)
+	defer stop()
+
+	db, err := sql.Open("mdb", "system:biglove@tcp("+addr+")/master?timeout=5s&multiStatements=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec("A; B")
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || batchErr.Index != 1 {
+		t.Fatalf("expected the second statement to fail, got %v", err)
+	}
+	srv.mu.Lock()
+	if want := []string{"A", "B"}; !reflect.DeepEqual(srv.statements, want) {
+		t.Errorf("expected the statements %q, got %q", want, srv.statements)
+	}
+	srv.statements = nil
+	srv.mu.Unlock()
+
+	// The session of a transaction can not be restored, the driver reports
+	// a bad connection. The script has partly run, it must not be retried.
+	conn := connectTo(t, addr, "&multiStatements=true")
+	defer conn.Close()
+	if _, err = conn.BeginTx(context.Background(), driver.TxOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	res, err := conn.ExecContext(context.Background(), "C; D", nil)
+	if err == nil || errors.Is(err, driver.ErrBadConn) {
+		t.Fatalf("expected an error other than driver.ErrBadConn, got %v", err)
+	}
+	if got := res.(*Result).AllRowsAffected(); !reflect.DeepEqual(got, []int64{1}) {
+		t.Errorf("expected affected rows [1], got %v", got)
+	}
+}
+
+func TestTemporalColumns(t *testing.T) {
+	var (
+		loc     = time.FixedZone("UTC+2", 2*60*60)
+		instant = time.Date(2021, time.March, 4, 5, 6, 7, 123456789, time.UTC)
+
+		timestamp = make([]byte, 8)
+		datetime  = make([]byte, 8)
+		date      = []byte{0, 0, 3, 4}
+		timeOfDay = []byte{5, 6, 7}
+	)
+	binary.LittleEndian.PutUint64(timestamp, uint64(instant.UnixNano()))
+	binary.LittleEndian.PutUint64(datetime, uint64(instant.Unix()))
+	binary.LittleEndian.PutUint16(date, 2021)
+
+	for _, tc := range []struct {
+		col      []byte
+		datatype odbc.Datatype
+		wantTime time.Time
+		wantText string
+	}{
+		{timestamp, odbc.Datatype_TIMESTAMP, instant.In(loc), "2021-03-04 07:06:07.123456789"},
+		{datetime, odbc.Datatype_DATETIME, instant.Truncate(time.Second).In(loc), "2021-03-04 07:06:07"},
+		{date, odbc.Datatype_DATE, time.Date(2021, time.March, 4, 0, 0, 0, 0, loc), "2021-03-04"},
+		{timeOfDay, odbc.Datatype_TIME, time.Date(0, time.January, 1, 5, 6, 7, 0, loc), "05:06:07"},
+	} {
+		cfg := NewConfig()
+		cfg.Loc = loc
+
+		got, ok := convertColumnToValue(tc.col, tc.datatype, cfg).(time.Time)
+		if !ok || !got.Equal(tc.wantTime) || got.Location() != loc {
+			t.Errorf("%s: expected %v, got %v", tc.datatype, tc.wantTime, got)
+		}
+
+		cfg.TemporalAsText = true
+		if got := convertColumnToValue(tc.col, tc.datatype, cfg); got != tc.wantText {
+			t.Errorf("%s: expected %q, got %q", tc.datatype, tc.wantText, got)
+		}
+	}
+}
+
+func TestTemporalConfigLiteral(t *testing.T) {
+	col := make([]byte, 8)
+	binary.LittleEndian.PutUint64(col, uint64(time.Date(2021, time.March, 4, 5, 6, 7, 0, time.UTC).Unix()))
+
+	srv := &fakeServer{
+		query: func(req *odbc.QueryRequest, stream odbc.MDBService_QueryServer) error {
+			return stream.Send(&odbc.QueryResponse{
+				RespSchema: &odbc.Schema{
+					ColumnName: []string{"at"},
+					ColumnType: []odbc.Datatype{odbc.Datatype_DATETIME},
+				},
+				ResultSet: []*odbc.Row{{Columns: [][]byte{col}, NullColumnBitmap: []byte{0}}},
+				Done:      true,
+			})
+		},
+	}
+	addr, stop := startServer(t, srv)
+	defer stop()
+
+	// A Config literal leaves every field at its zero value, temporal
+	// columns are still decoded to time.Time.
+	connector, err := NewConnector(&Config{User: "system", Password: "biglove", Net: "tcp", Addr: addr, DBName: "master"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	var at interface{}
+	if err = db.QueryRow("SELECT at FROM t").Scan(&at); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := at.(time.Time); !ok {
+		t.Errorf("expected a time.Time, got %T", at)
+	}
+}
+
+func TestTemporalArgs(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Loc = time.FixedZone("UTC+2", 2*60*60)
+	conn := &Conn{cfg: cfg}
+
+	// A TIME column value is sent back as the time of day it was read as
+	column := convertColumnToValue([]byte{5, 6, 7}, odbc.Datatype_TIME, cfg)
+
+	for _, tc := range []struct {
+		arg  interface{}
+		want string
+	}{
+		{time.Date(2021, time.March, 4, 5, 6, 7, 123456789, time.UTC), `"2021-03-04 07:06:07.123456789"`},
+		{time.Date(2021, time.March, 3, 22, 0, 0, 0, time.UTC), `"2021-03-04"`},
+		{time.Time{}, "NULL"},
+		{column, `"05:06:07"`},
+		{Typed(odbc.Datatype_TIME, column), `"05:06:07"`},
+		{Typed(odbc.Datatype_TIME, time.Date(2021, time.March, 4, 5, 6, 7, 0, time.UTC)), `"07:06:07"`},
+	} {
+		v, err := convertArg(tc.arg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := conn.appendValue(nil, v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tc.want {
+			t.Errorf("%v: expected %s, got %s", tc.arg, tc.want, got)
+		}
+	}
+}
+
+// encodingServer records the grpc-encoding of the requests it receives.
+type encodingServer struct {
+	fakeServer
+	encodings []string
+}
+
+func (s *encodingServer) record(ctx context.Context) {
+	// gRPC strips the grpc-encoding header from the incoming metadata, the
+	// transport stream still knows it.
+	var encoding string
+	if stream, ok := grpc.ServerTransportStreamFromContext(ctx).(interface{ RecvCompress() string }); ok {
+		encoding = stream.RecvCompress()
+	}
+
+	s.mu.Lock()
+	s.encodings = append(s.encodings, encoding)
+	s.mu.Unlock()
+}
+
+func (s *encodingServer) Exec(ctx context.Context, req *odbc.ExecRequest) (*odbc.ExecResponse, error) {
+	s.record(ctx)
+	return s.fakeServer.Exec(ctx, req)
+}
+
+func (s *encodingServer) Query(req *odbc.QueryRequest, stream odbc.MDBService_QueryServer) error {
+	s.record(stream.Context())
+	return s.fakeServer.Query(req, stream)
+}
+
+func TestCompression(t *testing.T) {
+	srv := &encodingServer{}
+	addr, stop := startServer(t, srv)
+	defer stop()
+
+	conn := connectTo(t, addr, "&compress=gzip&compressThreshold=32")
+	defer conn.Close()
+
+	var (
+		ctx   = context.Background()
+		large = "DELETE FROM numbers WHERE n > 1000000000"
+	)
+	if _, err := conn.ExecContext(ctx, "DELETE FROM numbers", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.ExecContext(ctx, large, nil); err != nil {
+		t.Fatal(err)
+	}
+	// Queries are compressed regardless of size, the server compresses its
+	// responses with the same compressor.
+	rows, err := conn.QueryContext(ctx, "SELECT 1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if want := []string{"", "gzip", "gzip"}; !reflect.DeepEqual(srv.encodings, want) {
+		t.Errorf("expected encodings %q, got %q", want, srv.encodings)
+	}
+}
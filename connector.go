@@ -3,45 +3,176 @@ package mdb
 import (
 	"context"
 	"database/sql/driver"
+	"errors"
 	"github.com/blockpointSystems/protocol-buffers/v1/odbc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"sync"
 )
 
+var errConnectorClosed = errors.New("connector is closed")
+
 type connector struct {
-	cfg *Config // immutable private copy.
+	cfg   *Config // immutable private copy.
+	hosts *hostPool
+
+	// Channels shared by the connections to each host
+	mu       sync.Mutex
+	channels map[string]*channel
+	closed   bool
+}
+
+// channel is a gRPC channel multiplexing the connections of a connector
+// to a single host.
+type channel struct {
+	*grpc.ClientConn
+	refs int
+}
+
+func newConnector(cfg *Config) *connector {
+	return &connector{
+		cfg:      cfg,
+		hosts:    newHostPool(cfg),
+		channels: make(map[string]*channel),
+	}
 }
 
 // Connect implements driver.Connector interface.
 // Connect returns a connection to the database.
+//
+// If the address lists several hosts they are tried in the order given by
+// the host strategy until one accepts the connection.
 func (c *connector) Connect(ctx context.Context) (conn driver.Conn, err error) {
+	for _, h := range c.hosts.candidates() {
+		conn, err = c.connect(ctx, h)
+		if err == nil || err == errConnectorClosed || ctx.Err() != nil {
+			return
+		}
+	}
+	return
+}
+
+func (c *connector) connect(ctx context.Context, h *host) (conn driver.Conn, err error) {
 	var (
-		mdbConn  *Conn
-		grpcConn *grpc.ClientConn
+		mdbConn *Conn
+		ch      *channel
 	)
 
-	grpcConn, err = grpc.Dial(c.cfg.Addr, grpc.WithInsecure())
+	ch, err = c.acquire(ctx, h.addr)
 	if err != nil {
+		// A dial given up by the caller says nothing about the host
+		if err != errConnectorClosed && ctx.Err() == nil {
+			h.markDown()
+		}
 		return
 	}
 
 	mdbConn = &Conn{
 		cfg:              c.cfg,
-		MDBServiceClient: odbc.NewMDBServiceClient(grpcConn),
+		host:             h,
+		MDBServiceClient: odbc.NewMDBServiceClient(ch),
+		channel:          ch.ClientConn,
+		release: func() {
+			c.release(h.addr, ch)
+		},
+	}
+	if c.cfg.StmtCacheSize > 0 {
+		mdbConn.stmtCache = newStmtCache(c.cfg.StmtCacheSize)
 	}
 
-	err = mdbConn.configureConnection()
+	err = mdbConn.configureConnection(ctx)
 	if err != nil {
 		// Close the connection and return
-		grpcConn.Close()
+		mdbConn.releaseChannel()
+		mdbConn.markBadConn(err)
 		return
 	}
 
+	h.markUp()
 	conn = mdbConn
 	return
 }
 
+// acquire returns the channel to addr, dialing it if there is none yet.
+func (c *connector) acquire(ctx context.Context, addr string) (ch *channel, err error) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil, errConnectorClosed
+	}
+	if ch = c.channels[addr]; ch != nil && ch.GetState() != connectivity.TransientFailure {
+		ch.refs++
+		c.mu.Unlock()
+		return
+	}
+	c.mu.Unlock()
+
+	// Dial without holding the lock, connections to other hosts must not
+	// wait for it.
+	grpcConn, err := c.dial(ctx, addr)
+	if err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		grpcConn.Close()
+		return nil, errConnectorClosed
+	}
+
+	// The previous channel failed or another connection dialed concurrently.
+	if ch = c.channels[addr]; ch != nil {
+		if ch.GetState() != connectivity.TransientFailure {
+			grpcConn.Close()
+			ch.refs++
+			return
+		}
+		// Connections still holding the failed channel close it on release.
+		delete(c.channels, addr)
+	}
+
+	ch = &channel{ClientConn: grpcConn, refs: 1}
+	c.channels[addr] = ch
+	return
+}
+
+// release drops a reference to ch, closing it once no connection uses it.
+func (c *connector) release(addr string, ch *channel) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch.refs--
+	if ch.refs > 0 {
+		return
+	}
+
+	if c.channels[addr] == ch {
+		delete(c.channels, addr)
+	}
+	ch.Close()
+}
+
+// Close implements io.Closer. It closes the gRPC channels of the connector,
+// after which no new connections can be made. database/sql calls it from
+// DB.Close once every connection has been closed.
+func (c *connector) Close() (err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	for addr, ch := range c.channels {
+		if closeErr := ch.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+		delete(c.channels, addr)
+	}
+	return
+}
+
 // Driver implements driver.Connector interface.
 // Driver returns &MDBDriver{}.
 func (c *connector) Driver() driver.Driver {
 	return &MDBDriver{}
-}
\ No newline at end of file
+}
@@ -3,12 +3,14 @@ package mdb
 import (
 	"database/sql/driver"
 	"github.com/blockpointSystems/protocol-buffers/v1/odbc"
+	"time"
 )
 
 const (
 	DEFAULT_ADDR_PORT = "8080"
 	DEFAULT_MAX_ROWS = int32(-1)
 	DEFAULT_BATCH_SIZE = 10
+	DEFAULT_HOST_BACKOFF = time.Second
 )
 
 var (
@@ -29,7 +31,13 @@ const (
 	defaultMaxAllowedPacket = 4 << 20 // 4 MiB
 	minProtocolVersion      = 10
 	maxPacketSize           = 1<<24 - 1
+	maxHostBackoff          = time.Minute
+	maxByteArrayList        = 64 // Longer byte arrays are written in hex
 	timeFormat              = "2006-01-02 15:04:05.999999"
+	timestampFormat         = "2006-01-02 15:04:05.999999999"
+	datetimeFormat          = "2006-01-02 15:04:05"
+	dateFormat              = "2006-01-02"
+	timeOfDayFormat         = "15:04:05"
 )
 
 // MySQL constants documentation:
@@ -4,21 +4,64 @@ import (
 	"context"
 	"database/sql"
 	"database/sql/driver"
+	"net"
+	"sync"
 )
 
 // MDBDriver is exported to make the driver directly accessible.
 // In general the driver is used via the database/sql package.
 type MDBDriver struct {}
 
+// DialContextFunc is a function which can be used to establish the network connection.
+// Custom dial functions must be registered with RegisterDialContext
+type DialContextFunc func(ctx context.Context, addr string) (net.Conn, error)
+
+// Registry for custom dial functions
+var (
+	dialsLock sync.RWMutex
+	dials     map[string]DialContextFunc
+)
+
+// RegisterDialContext registers a custom dial function. It can then be used by the
+// network address mynet(addr), where mynet is the registered new network.
+// The current context for the connection and its address is passed to the dial function.
+//
+//  mdb.RegisterDialContext("ssh", func(ctx context.Context, addr string) (net.Conn, error) {
+//  	return sshClient.Dial("tcp", addr)
+//  })
+//  db, err := sql.Open("mdb", "system:password@ssh(10.0.0.5:5461)/main")
+//
+func RegisterDialContext(net string, dial DialContextFunc) {
+	dialsLock.Lock()
+	if dials == nil {
+		dials = make(map[string]DialContextFunc)
+	}
+	dials[net] = dial
+	dialsLock.Unlock()
+}
+
+// DeregisterDialContext removes the dial function registered for net.
+func DeregisterDialContext(net string) {
+	dialsLock.Lock()
+	if dials != nil {
+		delete(dials, net)
+	}
+	dialsLock.Unlock()
+}
+
+func getDialContext(net string) (dial DialContextFunc) {
+	dialsLock.RLock()
+	dial = dials[net]
+	dialsLock.RUnlock()
+	return
+}
+
 func (mdb *MDBDriver) Open(dsn string) (driver.Conn, error) {
 	cfg, err := ParseDSN(dsn)
 	if err != nil {
 		return nil, err
 	}
-	c := &connector{
-		cfg: cfg,
-	}
-	return c.Connect(context.Background())
+	return newConnector(cfg).Connect(context.Background())
 }
 
 func init() {
@@ -34,7 +77,7 @@ func NewConnector(cfg *Config) (driver.Connector, error) {
 		return nil, err
 	}
 
-	return &connector{cfg: cfg}, nil
+	return newConnector(cfg), nil
 }
 
 // OpenConnector implements driver.DriverContext.
@@ -45,8 +88,6 @@ func (d MDBDriver) OpenConnector(dsn string) (conn driver.Connector, err error)
 		return
 	}
 
-	conn = &connector{
-		cfg: cfg,
-	}
+	conn = newConnector(cfg)
 	return
 }
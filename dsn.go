@@ -2,13 +2,16 @@ package mdb
 
 import (
 	"bytes"
+	"context"
 	"crypto/rsa"
 	"crypto/tls"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"math/big"
 	"net"
 	"net/url"
+	"os"
 	"sort"
 	"strconv"
 	"strings"
@@ -28,8 +31,12 @@ var (
 type Config struct {
 	User             string            // Username
 	Password         string            // Password (requires User)
+	PasswordFile     string            // File the password is read from for every connection
+	PasswordEnv      string            // Environment variable the password is read from for every connection
 	Net              string            // Network type
-	Addr             string            // Network address (requires Net)
+	Addr             string            // Network address (requires Net), may list several comma separated hosts
+	HostStrategy     string            // Host selection strategy for multi-host addresses
+	HostBackoff      time.Duration     // Initial back-off for a host which failed
 	DBName           string            // Database name
 	Params           map[string]string // Connection parameters
 	// TODO: Add Support
@@ -40,9 +47,17 @@ type Config struct {
 	pubKey           *rsa.PublicKey    // Server public key
 	TLSConfig        string            // TLS configuration name
 	tls              *tls.Config       // TLS configuration
-	Timeout          time.Duration     // Dial timeout
+	Timeout          time.Duration     // Dial timeout, 20s if not set
 	ReadTimeout      time.Duration     // I/O read timeout
 	WriteTimeout     time.Duration     // I/O write timeout
+	StmtCacheSize    int               // Prepared statements cached per connection, 0 disables the cache
+
+	Compress          string // gRPC compressor name, compression is disabled if empty
+	CompressThreshold int    // Minimum statement size for compressing Exec requests
+
+	KeepaliveTime                time.Duration // Idle time before the channel is pinged
+	KeepaliveTimeout             time.Duration // Time to wait for a keepalive ping ack
+	KeepalivePermitWithoutStream bool          // Send keepalive pings without active RPCs
 
 	// TODO: Add Support
 	//AllowAllFiles           bool // Allow all files to be used with LOAD DATA LOCAL INFILE
@@ -50,13 +65,25 @@ type Config struct {
 	ClientFoundRows         bool // Return number of matching rows instead of rows changed
 	//ColumnsWithAlias        bool // Prepend table alias to column names
 	InterpolateParams       bool // Interpolate placeholders into query string
-	//MultiStatements         bool // Allow multiple statements in one query
+	MultiStatements         bool // Allow multiple statements in one query
 	MaxRowCount,
 	FetchSize int32
-	ParseTime               bool // Parse time values to time.Time
+	ParseTime               bool // Deprecated: temporal values are always time.Time unless TemporalAsText is set
+	TemporalAsText          bool // Return temporal values as text instead of time.Time, set by parseTime=false
+	Uint64AsString          bool // Return UINT64 values as decimal strings instead of uint64
 	RejectReadOnly          bool // Reject read-only connections
+
+	// CredentialProvider, if set, supplies the credentials for every new
+	// connection in place of User and Password.
+	CredentialProvider CredentialProvider
 }
 
+// CredentialProvider returns the username and password used to open a
+// connection. It is called for every new connection and whenever an
+// expired session is re-established, so rotated secrets are picked up
+// without reopening the sql.DB.
+type CredentialProvider func(ctx context.Context) (user, password string, err error)
+
 // NewConfig creates a new Config and sets default values.
 func NewConfig() *Config {
 	return &Config{
@@ -64,6 +91,8 @@ func NewConfig() *Config {
 		Loc:                  time.UTC,
 		MaxAllowedPacket:     defaultMaxAllowedPacket,
 		CheckConnLiveness:    true,
+		HostStrategy:         HostStrategyFailover,
+		HostBackoff:          DEFAULT_HOST_BACKOFF,
 		FetchSize: DEFAULT_BATCH_SIZE,
 		MaxRowCount: DEFAULT_MAX_ROWS,
 	}
@@ -91,10 +120,19 @@ func (cfg *Config) Clone() *Config {
 }
 
 func (cfg *Config) normalize() error {
+	if cfg.PasswordFile != "" && cfg.PasswordEnv != "" {
+		return errors.New("passwordFile and passwordEnv are mutually exclusive")
+	}
+
 	//if cfg.InterpolateParams && unsafeCollations[cfg.Collation] {
 	//	return errInvalidDSNUnsafeCollation
 	//}
 
+	// Values are decoded and encoded in UTC by default
+	if cfg.Loc == nil {
+		cfg.Loc = time.UTC
+	}
+
 	// Set default network if empty
 	if cfg.Net == "" {
 		cfg.Net = "tcp"
@@ -111,7 +149,21 @@ func (cfg *Config) normalize() error {
 			return errors.New("default addr for network '" + cfg.Net + "' unknown")
 		}
 	} else if cfg.Net == "tcp" {
-		cfg.Addr = ensureHavePort(cfg.Addr)
+		addrs := splitAddrs(cfg.Addr)
+		for i := range addrs {
+			addrs[i] = ensureHavePort(addrs[i])
+		}
+		cfg.Addr = strings.Join(addrs, ",")
+	}
+
+	if cfg.HostStrategy == "" {
+		cfg.HostStrategy = HostStrategyFailover
+	} else if !isValidHostStrategy(cfg.HostStrategy) {
+		return errors.New("invalid host strategy: " + cfg.HostStrategy)
+	}
+
+	if cfg.HostBackoff <= 0 {
+		cfg.HostBackoff = DEFAULT_HOST_BACKOFF
 	}
 
 	switch cfg.TLSConfig {
@@ -128,13 +180,18 @@ func (cfg *Config) normalize() error {
 		}
 	}
 
-	if cfg.tls != nil && cfg.tls.ServerName == "" && !cfg.tls.InsecureSkipVerify {
+	// With several hosts the server name is inferred per host when dialing
+	if cfg.tls != nil && cfg.tls.ServerName == "" && !cfg.tls.InsecureSkipVerify && !strings.Contains(cfg.Addr, ",") {
 		host, _, err := net.SplitHostPort(cfg.Addr)
 		if err == nil {
 			cfg.tls.ServerName = host
 		}
 	}
 
+	if cfg.Compress != "" && !isRegisteredCompressor(cfg.Compress) {
+		return errors.New("invalid value / unknown compressor name: " + cfg.Compress)
+	}
+
 	if cfg.ServerPubKey != "" {
 		cfg.pubKey = getServerPubKey(cfg.ServerPubKey)
 		if cfg.pubKey == nil {
@@ -145,6 +202,32 @@ func (cfg *Config) normalize() error {
 	return nil
 }
 
+// credentials resolves the username and password for a new connection.
+func (cfg *Config) credentials(ctx context.Context) (user, password string, err error) {
+	switch {
+	case cfg.CredentialProvider != nil:
+		return cfg.CredentialProvider(ctx)
+
+	case cfg.PasswordFile != "":
+		var data []byte
+		data, err = ioutil.ReadFile(cfg.PasswordFile)
+		if err != nil {
+			return
+		}
+		return cfg.User, strings.TrimRight(string(data), "\r\n"), nil
+
+	case cfg.PasswordEnv != "":
+		var isSet bool
+		password, isSet = os.LookupEnv(cfg.PasswordEnv)
+		if !isSet {
+			return "", "", errors.New("password environment variable not set: " + cfg.PasswordEnv)
+		}
+		return cfg.User, password, nil
+	}
+
+	return cfg.User, cfg.Password, nil
+}
+
 func writeDSNParam(buf *bytes.Buffer, hasParam *bool, name, value string) {
 	buf.Grow(1 + len(name) + 1 + len(value))
 	if !*hasParam {
@@ -203,6 +286,14 @@ func (cfg *Config) FormatDSN() string {
 		writeDSNParam(&buf, &hasParam, "clientFoundRows", "true")
 	}
 
+	if len(cfg.Compress) > 0 {
+		writeDSNParam(&buf, &hasParam, "compress", url.QueryEscape(cfg.Compress))
+	}
+
+	if cfg.CompressThreshold > 0 {
+		writeDSNParam(&buf, &hasParam, "compressThreshold", strconv.Itoa(cfg.CompressThreshold))
+	}
+
 	//if col := cfg.Collation; col != defaultCollation && len(col) > 0 {
 	//	writeDSNParam(&buf, &hasParam, "collation", col)
 	//}
@@ -211,16 +302,48 @@ func (cfg *Config) FormatDSN() string {
 	//	writeDSNParam(&buf, &hasParam, "columnsWithAlias", "true")
 	//}
 
+	if cfg.HostBackoff > 0 && cfg.HostBackoff != DEFAULT_HOST_BACKOFF {
+		writeDSNParam(&buf, &hasParam, "hostBackoff", cfg.HostBackoff.String())
+	}
+
+	if cfg.HostStrategy != "" && cfg.HostStrategy != HostStrategyFailover {
+		writeDSNParam(&buf, &hasParam, "hostStrategy", cfg.HostStrategy)
+	}
+
 	if cfg.InterpolateParams {
 		writeDSNParam(&buf, &hasParam, "interpolateParams", "true")
 	}
 
+	if cfg.KeepalivePermitWithoutStream {
+		writeDSNParam(&buf, &hasParam, "keepalivePermitWithoutStream", "true")
+	}
+
+	if cfg.KeepaliveTime > 0 {
+		writeDSNParam(&buf, &hasParam, "keepaliveTime", cfg.KeepaliveTime.String())
+	}
+
+	if cfg.KeepaliveTimeout > 0 {
+		writeDSNParam(&buf, &hasParam, "keepaliveTimeout", cfg.KeepaliveTimeout.String())
+	}
+
 	if cfg.Loc != time.UTC && cfg.Loc != nil {
 		writeDSNParam(&buf, &hasParam, "loc", url.QueryEscape(cfg.Loc.String()))
 	}
 
-	if cfg.ParseTime {
-		writeDSNParam(&buf, &hasParam, "parseTime", "true")
+	if cfg.MultiStatements {
+		writeDSNParam(&buf, &hasParam, "multiStatements", "true")
+	}
+
+	if cfg.TemporalAsText {
+		writeDSNParam(&buf, &hasParam, "parseTime", "false")
+	}
+
+	if len(cfg.PasswordEnv) > 0 {
+		writeDSNParam(&buf, &hasParam, "passwordEnv", url.QueryEscape(cfg.PasswordEnv))
+	}
+
+	if len(cfg.PasswordFile) > 0 {
+		writeDSNParam(&buf, &hasParam, "passwordFile", url.QueryEscape(cfg.PasswordFile))
 	}
 
 	if cfg.ReadTimeout > 0 {
@@ -235,6 +358,10 @@ func (cfg *Config) FormatDSN() string {
 		writeDSNParam(&buf, &hasParam, "serverPubKey", url.QueryEscape(cfg.ServerPubKey))
 	}
 
+	if cfg.StmtCacheSize > 0 {
+		writeDSNParam(&buf, &hasParam, "stmtCacheSize", strconv.Itoa(cfg.StmtCacheSize))
+	}
+
 	if cfg.Timeout > 0 {
 		writeDSNParam(&buf, &hasParam, "timeout", cfg.Timeout.String())
 	}
@@ -243,6 +370,10 @@ func (cfg *Config) FormatDSN() string {
 		writeDSNParam(&buf, &hasParam, "tls", url.QueryEscape(cfg.TLSConfig))
 	}
 
+	if cfg.Uint64AsString {
+		writeDSNParam(&buf, &hasParam, "uint64AsString", "true")
+	}
+
 	if cfg.WriteTimeout > 0 {
 		writeDSNParam(&buf, &hasParam, "writeTimeout", cfg.WriteTimeout.String())
 	}
@@ -408,7 +539,41 @@ func parseDSNParams(cfg *Config, params string) (err error) {
 
 		// Compression
 		case "compress":
-			return errors.New("compression not implemented yet")
+			boolValue, isBool := parseBool(value)
+			if isBool {
+				if boolValue {
+					cfg.Compress = "gzip"
+				} else {
+					cfg.Compress = ""
+				}
+			} else {
+				name, err := url.QueryUnescape(value)
+				if err != nil {
+					return fmt.Errorf("invalid value for compressor name: %v", err)
+				}
+				cfg.Compress = name
+			}
+
+		// Minimum statement size for compression
+		case "compressThreshold":
+			cfg.CompressThreshold, err = strconv.Atoi(value)
+			if err != nil {
+				return
+			}
+
+		// Initial back-off for failed hosts
+		case "hostBackoff":
+			cfg.HostBackoff, err = time.ParseDuration(value)
+			if err != nil {
+				return
+			}
+
+		// Host selection strategy
+		case "hostStrategy":
+			if !isValidHostStrategy(value) {
+				return errors.New("invalid host strategy: " + value)
+			}
+			cfg.HostStrategy = value
 
 		// Enable client side placeholder substitution
 		case "interpolateParams":
@@ -418,6 +583,28 @@ func parseDSNParams(cfg *Config, params string) (err error) {
 				return errors.New("invalid bool value: " + value)
 			}
 
+		// Send keepalive pings while no RPC is active
+		case "keepalivePermitWithoutStream":
+			var isBool bool
+			cfg.KeepalivePermitWithoutStream, isBool = parseBool(value)
+			if !isBool {
+				return errors.New("invalid bool value: " + value)
+			}
+
+		// Keepalive ping interval
+		case "keepaliveTime":
+			cfg.KeepaliveTime, err = time.ParseDuration(value)
+			if err != nil {
+				return
+			}
+
+		// Keepalive ping ack timeout
+		case "keepaliveTimeout":
+			cfg.KeepaliveTimeout, err = time.ParseDuration(value)
+			if err != nil {
+				return
+			}
+
 		// Time Location
 		case "loc":
 			if value, err = url.QueryUnescape(value); err != nil {
@@ -428,6 +615,14 @@ func parseDSNParams(cfg *Config, params string) (err error) {
 				return
 			}
 
+		// multiple statements in one query
+		case "multiStatements":
+			var isBool bool
+			cfg.MultiStatements, isBool = parseBool(value)
+			if !isBool {
+				return errors.New("invalid bool value: " + value)
+			}
+
 		// time.Time parsing
 		case "parseTime":
 			var isBool bool
@@ -435,6 +630,18 @@ func parseDSNParams(cfg *Config, params string) (err error) {
 			if !isBool {
 				return errors.New("invalid bool value: " + value)
 			}
+			cfg.TemporalAsText = !cfg.ParseTime
+
+		// Password sources
+		case "passwordEnv":
+			if cfg.PasswordEnv, err = url.QueryUnescape(value); err != nil {
+				return
+			}
+
+		case "passwordFile":
+			if cfg.PasswordFile, err = url.QueryUnescape(value); err != nil {
+				return
+			}
 
 		// I/O read Timeout
 		case "readTimeout":
@@ -459,6 +666,13 @@ func parseDSNParams(cfg *Config, params string) (err error) {
 			}
 			cfg.ServerPubKey = name
 
+		// Prepared statement cache
+		case "stmtCacheSize":
+			cfg.StmtCacheSize, err = strconv.Atoi(value)
+			if err != nil || cfg.StmtCacheSize < 0 {
+				return errors.New("invalid stmtCacheSize value: " + value)
+			}
+
 		// Strict mode
 		case "strict":
 			panic("strict mode has been removed. See https://github.com/go-sql-driver/mysql/wiki/strict-mode")
@@ -489,6 +703,14 @@ func parseDSNParams(cfg *Config, params string) (err error) {
 				cfg.TLSConfig = name
 			}
 
+		// UINT64 values as decimal strings
+		case "uint64AsString":
+			var isBool bool
+			cfg.Uint64AsString, isBool = parseBool(value)
+			if !isBool {
+				return errors.New("invalid bool value: " + value)
+			}
+
 		// I/O write Timeout
 		case "writeTimeout":
 			cfg.WriteTimeout, err = time.ParseDuration(value)
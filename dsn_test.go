@@ -0,0 +1,118 @@
+package mdb
+
+import (
+	"context"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseDSNParams(t *testing.T) {
+	for _, tc := range []struct {
+		dsn   string
+		check func(cfg *Config) bool
+	}{
+		{
+			"system:biglove@tcp(h1,h2:5461)/main?hostStrategy=roundRobin&hostBackoff=5s",
+			func(cfg *Config) bool {
+				return cfg.Addr == "h1:"+DEFAULT_ADDR_PORT+",h2:5461" && cfg.HostStrategy == HostStrategyRoundRobin
+			},
+		},
+		{
+			"system:biglove@tcp(localhost)/main?compress=true",
+			func(cfg *Config) bool { return cfg.Compress == "gzip" },
+		},
+		{
+			"system:biglove@tcp(localhost)/main?compress=gzip&compressThreshold=1024",
+			func(cfg *Config) bool { return cfg.Compress == "gzip" && cfg.CompressThreshold == 1024 },
+		},
+		{
+			"system:biglove@tcp(localhost)/main?keepaliveTime=30s&keepalivePermitWithoutStream=true",
+			func(cfg *Config) bool { return cfg.KeepaliveTime.Seconds() == 30 && cfg.KeepalivePermitWithoutStream },
+		},
+		{
+			"system:biglove@tcp(localhost)/main?multiStatements=true",
+			func(cfg *Config) bool { return cfg.MultiStatements },
+		},
+		{
+			"system:biglove@tcp(localhost)/main",
+			func(cfg *Config) bool { return !cfg.TemporalAsText && cfg.Loc == time.UTC },
+		},
+		{
+			"system:biglove@tcp(localhost)/main?parseTime=false&loc=Europe%2FBerlin",
+			func(cfg *Config) bool { return cfg.TemporalAsText && cfg.Loc.String() == "Europe/Berlin" },
+		},
+		{
+			"system:biglove@tcp(localhost)/main?uint64AsString=true",
+			func(cfg *Config) bool { return cfg.Uint64AsString },
+		},
+		{
+			"system:biglove@tcp(localhost)/main?stmtCacheSize=64",
+			func(cfg *Config) bool { return cfg.StmtCacheSize == 64 },
+		},
+	} {
+		cfg, err := ParseDSN(tc.dsn)
+		if err != nil {
+			t.Errorf("%s: %v", tc.dsn, err)
+			continue
+		}
+		if !tc.check(cfg) {
+			t.Errorf("%s: unexpected config %+v", tc.dsn, cfg)
+		}
+
+		// The formatted DSN must parse to the same config
+		formatted := cfg.FormatDSN()
+		if reparsed, err := ParseDSN(formatted); err != nil || reparsed.FormatDSN() != formatted {
+			t.Errorf("%s: FormatDSN does not round trip: %s", tc.dsn, formatted)
+		}
+	}
+}
+
+func TestParseDSNInvalidParams(t *testing.T) {
+	for _, dsn := range []string{
+		"system:biglove@tcp(localhost)/main?compress=lz4",
+		"system:biglove@tcp(localhost)/main?hostStrategy=fastest",
+		"system:biglove@tcp(localhost)/main?stmtCacheSize=-1",
+	} {
+		if _, err := ParseDSN(dsn); err == nil {
+			t.Errorf("%s: expected an error", dsn)
+		}
+	}
+}
+
+func TestConfigCredentials(t *testing.T) {
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	if err := ioutil.WriteFile(passwordFile, []byte("first\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := ParseDSN("system@tcp(localhost)/main?passwordFile=" + url.QueryEscape(passwordFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"first", "rotated"} {
+		if want == "rotated" {
+			if err = ioutil.WriteFile(passwordFile, []byte(want), 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		user, password, err := cfg.credentials(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if user != "system" || password != want {
+			t.Errorf("expected system:%s, got %s:%s", want, user, password)
+		}
+	}
+
+	cfg.CredentialProvider = func(ctx context.Context) (string, string, error) {
+		return "vault", "secret", nil
+	}
+	if user, password, _ := cfg.credentials(context.Background()); user != "vault" || password != "secret" {
+		t.Errorf("expected the credential provider to take precedence, got %s:%s", user, password)
+	}
+}
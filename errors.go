@@ -21,6 +21,16 @@ var (
 	ErrPktSyncMul        = errors.New("commands out of sync. Did you run multiple statements at once?")
 	ErrPktTooLarge       = errors.New("packet for query is too large. Try adjusting the 'max_allowed_packet' variable on the server")
 	ErrBusyBuffer        = errors.New("busy buffer")
+	ErrReadTimeout       = errors.New("timed out waiting for a response from the server")
+	ErrWriteTimeout      = errors.New("timed out sending the request to the server")
+	ErrStmtClosed        = errors.New("statement is closed")
+	ErrPlaceholderCount  = errors.New("number of placeholders does not match the number of arguments")
+	ErrOutOfRange        = errors.New("value out of range")
+
+	// ErrInterpolationRequired is returned for statements with arguments
+	// unless interpolateParams=true is set. The MDB protocol has no way to
+	// send arguments alongside a statement.
+	ErrInterpolationRequired = errors.New("arguments require interpolateParams=true, the server does not accept bound parameters")
 
 	// errBadConnNoWrite is used for connection errors where nothing was sent to the database yet.
 	// If this happens first in a function starting a database interaction, it should be replaced by driver.ErrBadConn
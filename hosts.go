@@ -0,0 +1,150 @@
+package mdb
+
+import (
+	"math/rand"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Host selection strategies for addresses listing several hosts,
+// e.g. tcp(h1:5461,h2:5461,h3:5461).
+const (
+	// HostStrategyFailover tries the hosts in the order they are listed.
+	HostStrategyFailover = "failover"
+	// HostStrategyRandom tries the hosts in a random order.
+	HostStrategyRandom = "random"
+	// HostStrategyRoundRobin starts each connection at the host after the
+	// one the previous connection started at.
+	HostStrategyRoundRobin = "roundRobin"
+)
+
+func isValidHostStrategy(strategy string) bool {
+	switch strategy {
+	case HostStrategyFailover, HostStrategyRandom, HostStrategyRoundRobin:
+		return true
+	}
+	return false
+}
+
+// splitAddrs returns the individual addresses of a multi-host address.
+func splitAddrs(addr string) []string {
+	addrs := strings.Split(addr, ",")
+	for i := range addrs {
+		addrs[i] = strings.TrimSpace(addrs[i])
+	}
+	return addrs
+}
+
+// host tracks the health of a single server address.
+type host struct {
+	addr    string
+	backoff time.Duration
+
+	mu        sync.Mutex
+	failures  uint
+	downUntil time.Time
+}
+
+// markDown records a failure, keeping the host out of rotation for an
+// exponentially growing back-off.
+func (h *host) markDown() {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	backoff := h.backoff
+	for i := uint(0); i < h.failures && backoff < maxHostBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxHostBackoff {
+		backoff = maxHostBackoff
+	}
+
+	h.failures++
+	h.downUntil = time.Now().Add(backoff)
+}
+
+// markUp records a successful connection.
+func (h *host) markUp() {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	h.failures = 0
+	h.downUntil = time.Time{}
+	h.mu.Unlock()
+}
+
+func (h *host) isDown(now time.Time) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return now.Before(h.downUntil)
+}
+
+// hostPool is the set of hosts a connector connects to.
+type hostPool struct {
+	strategy string
+	hosts    []*host
+
+	// next is the round-robin cursor.
+	next uint32
+}
+
+func newHostPool(cfg *Config) *hostPool {
+	var (
+		addrs = splitAddrs(cfg.Addr)
+		pool  = &hostPool{
+			strategy: cfg.HostStrategy,
+			hosts:    make([]*host, len(addrs)),
+		}
+	)
+
+	for i, addr := range addrs {
+		pool.hosts[i] = &host{
+			addr:    addr,
+			backoff: cfg.HostBackoff,
+		}
+	}
+	return pool
+}
+
+// candidates returns the hosts in the order they should be tried.
+// Hosts which are backing off are moved to the end, so they are only
+// tried once every healthy host has failed.
+func (p *hostPool) candidates() []*host {
+	var (
+		n     = len(p.hosts)
+		order = make([]*host, 0, n)
+	)
+
+	switch p.strategy {
+	case HostStrategyRandom:
+		for _, i := range rand.Perm(n) {
+			order = append(order, p.hosts[i])
+		}
+	case HostStrategyRoundRobin:
+		start := int(atomic.AddUint32(&p.next, 1)-1) % n
+		order = append(order, p.hosts[start:]...)
+		order = append(order, p.hosts[:start]...)
+	default:
+		order = append(order, p.hosts...)
+	}
+
+	var (
+		now     = time.Now()
+		healthy = make([]*host, 0, n)
+		down    []*host
+	)
+	for _, h := range order {
+		if h.isDown(now) {
+			down = append(down, h)
+		} else {
+			healthy = append(healthy, h)
+		}
+	}
+	return append(healthy, down...)
+}
@@ -0,0 +1,220 @@
+package mdb
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// placeholder is a parameter placeholder in a query. Positional
+// placeholders are written ?, named ones :name or @name.
+type placeholder struct {
+	pos, end int
+	name     string
+}
+
+// placeholders returns the placeholders of a bSQL query. Placeholders
+// inside literals and comments are ignored, see skipLiteral. Doubled
+// markers, as in a::int or @@var, are not placeholders.
+func placeholders(query string, noBackslashEscapes bool) (found []placeholder) {
+	for i := 0; i < len(query); i++ {
+		if end, _ := skipLiteral(query, i, noBackslashEscapes); end != i {
+			i = end
+			continue
+		}
+
+		switch c := query[i]; c {
+		case '?':
+			found = append(found, placeholder{pos: i, end: i + 1})
+
+		case ':', '@':
+			if i > 0 && query[i-1] == c {
+				continue
+			}
+			end := i + 1
+			for end < len(query) && isNameByte(query[end], end == i+1) {
+				end++
+			}
+			if end > i+1 {
+				found = append(found, placeholder{pos: i, end: end, name: query[i+1 : end]})
+				i = end - 1
+			}
+		}
+	}
+	return
+}
+
+// splitStatements splits a script into its statements, which are separated
+// by semicolons. Statements holding nothing but comments are dropped.
+func splitStatements(query string, noBackslashEscapes bool) (statements []string) {
+	var (
+		start   int
+		hasCode bool
+	)
+	for i := 0; i <= len(query); i++ {
+		if i == len(query) || query[i] == ';' {
+			if hasCode {
+				statements = append(statements, strings.TrimSpace(query[start:i]))
+			}
+			start, hasCode = i+1, false
+			continue
+		}
+
+		end, comment := skipLiteral(query, i, noBackslashEscapes)
+		if !comment && !isSpace(query[i]) {
+			hasCode = true
+		}
+		i = end
+	}
+	return
+}
+
+// skipLiteral returns the offset of the last byte of the literal or comment
+// starting at offset i, or i if there is none.
+//
+// Strings are quoted with ' or ", a quote is escaped by doubling it or, unless
+// noBackslashEscapes is set, with a backslash. Byte array literals are
+// enclosed in [ and ]. Comments run from -- to the end of the line or from
+// /* to */. An unterminated literal or comment extends to the end of the
+// query.
+func skipLiteral(query string, i int, noBackslashEscapes bool) (end int, comment bool) {
+	switch query[i] {
+	case '\'', '"':
+		return skipQuoted(query, i, noBackslashEscapes), false
+
+	case '[':
+		return skipUntil(query, i+1, "]"), false
+
+	case '-':
+		if i+1 < len(query) && query[i+1] == '-' {
+			return skipUntil(query, i+2, "\n"), true
+		}
+
+	case '/':
+		if i+1 < len(query) && query[i+1] == '*' {
+			return skipUntil(query, i+2, "*/"), true
+		}
+	}
+	return i, false
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// countInputs returns the number of arguments a query takes: the number of
+// distinct names if it uses named placeholders, otherwise the number of ?.
+// It returns -1 if both kinds are used.
+func countInputs(found []placeholder) int {
+	var (
+		positional int
+		names      = make(map[string]struct{})
+	)
+	for _, p := range found {
+		if p.name == "" {
+			positional++
+		} else {
+			names[p.name] = struct{}{}
+		}
+	}
+
+	switch {
+	case len(names) == 0:
+		return positional
+	case positional == 0:
+		return len(names)
+	}
+	return -1
+}
+
+func isNameByte(c byte, first bool) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || !first && '0' <= c && c <= '9'
+}
+
+// bindNamed maps the named arguments of a query to positions. Every named
+// placeholder is replaced with ?, and the value of its argument is
+// appended to the returned arguments, so a name may be used several times.
+// Queries without named arguments are returned as is.
+func bindNamed(query string, noBackslashEscapes bool, args []driver.NamedValue) (string, []driver.Value, error) {
+	var (
+		dargs = make([]driver.Value, 0, len(args))
+		named = make(map[string]driver.Value)
+	)
+	for _, arg := range args {
+		if arg.Name != "" {
+			named[arg.Name] = arg.Value
+		}
+		dargs = append(dargs, arg.Value)
+	}
+	if len(named) == 0 {
+		return query, dargs, nil
+	}
+	if len(named) != len(args) {
+		return "", nil, errors.New("mdb: named and positional arguments can not be mixed")
+	}
+
+	var (
+		buf  = make([]byte, 0, len(query))
+		last int
+		used = make(map[string]bool, len(named))
+	)
+	dargs = dargs[:0]
+
+	for _, p := range placeholders(query, noBackslashEscapes) {
+		if p.name == "" {
+			return "", nil, errors.New("mdb: ? placeholders can not be used with named arguments")
+		}
+		value, ok := named[p.name]
+		if !ok {
+			return "", nil, fmt.Errorf("mdb: no argument for placeholder %s", query[p.pos:p.end])
+		}
+
+		buf = append(buf, query[last:p.pos]...)
+		buf = append(buf, '?')
+		last = p.end
+
+		dargs = append(dargs, value)
+		used[p.name] = true
+	}
+	buf = append(buf, query[last:]...)
+
+	for _, arg := range args {
+		if !used[arg.Name] {
+			return "", nil, fmt.Errorf("mdb: named argument %q is not used by the query", arg.Name)
+		}
+	}
+	return string(buf), dargs, nil
+}
+
+// skipQuoted returns the offset of the quote closing the literal opened at
+// start, or the last offset of the query if it is not closed.
+func skipQuoted(query string, start int, noBackslashEscapes bool) int {
+	quote := query[start]
+	for i := start + 1; i < len(query); i++ {
+		switch query[i] {
+		case '\\':
+			if !noBackslashEscapes {
+				i++
+			}
+		case quote:
+			if i+1 < len(query) && query[i+1] == quote {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return len(query) - 1
+}
+
+// skipUntil returns the offset of the last byte of the first occurrence of
+// end at or after start, or the last offset of the query if there is none.
+func skipUntil(query string, start int, end string) int {
+	for i := start; i+len(end) <= len(query); i++ {
+		if query[i:i+len(end)] == end {
+			return i + len(end) - 1
+		}
+	}
+	return len(query) - 1
+}
@@ -0,0 +1,126 @@
+package mdb
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestPlaceholders(t *testing.T) {
+	for _, tc := range []struct {
+		query              string
+		noBackslashEscapes bool
+		want               []int
+	}{
+		{"SELECT * FROM t WHERE a = ? AND b = ?", false, []int{26, 36}},
+		{"SELECT '?', \"?\" FROM t WHERE a = ?", false, []int{33}},
+		{"SELECT 'it''s ?' FROM t WHERE a = ?", false, []int{34}},
+		{`SELECT 'a\'?' FROM t WHERE a = ?`, false, []int{31}},
+		{`SELECT 'a\'?' FROM t WHERE a = ?`, true, []int{11}},
+		{"INSERT INTO t VALUES ([63, 63], ?)", false, []int{32}},
+		{"SELECT a -- why?\nFROM t WHERE a = ?", false, []int{34}},
+		{"SELECT a /* ? */ FROM t WHERE a = ?", false, []int{34}},
+		{"SELECT a - ? FROM t", false, []int{11}},
+		{"SELECT '?", false, nil},
+		{"SELECT a /* ?", false, nil},
+		{"SELECT * FROM t WHERE a = :a OR b = @b_2", false, []int{26, 36}},
+		{"SELECT a::int, @@version, ':a' FROM t", false, nil},
+	} {
+		var got []int
+		for _, p := range placeholders(tc.query, tc.noBackslashEscapes) {
+			got = append(got, p.pos)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%q: expected %v, got %v", tc.query, tc.want, got)
+		}
+	}
+}
+
+func TestInterpolateParamsLiterals(t *testing.T) {
+	conn := &Conn{cfg: NewConfig()}
+
+	got, err := conn.interpolateParams("UPDATE t SET a = '?', b = ? -- c = ?", []driver.Value{int64(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "UPDATE t SET a = '?', b = 1 -- c = ?"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	_, err = conn.interpolateParams("UPDATE t SET a = ?, b = '?'", []driver.Value{int64(1), int64(2)})
+	if !errors.Is(err, ErrPlaceholderCount) {
+		t.Errorf("expected ErrPlaceholderCount, got %v", err)
+	}
+}
+
+func TestBindNamed(t *testing.T) {
+	args := []driver.NamedValue{
+		{Name: "id", Ordinal: 1, Value: int64(5)},
+		{Name: "name", Ordinal: 2, Value: "x"},
+	}
+
+	query, dargs, err := bindNamed("AMEND t SET name = @name WHERE id = :id OR parent = :id", false, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "AMEND t SET name = ? WHERE id = ? OR parent = ?"; query != want {
+		t.Errorf("expected %q, got %q", want, query)
+	}
+	if want := []driver.Value{"x", int64(5), int64(5)}; !reflect.DeepEqual(dargs, want) {
+		t.Errorf("expected %v, got %v", want, dargs)
+	}
+
+	for _, query := range []string{
+		"SELECT * FROM t WHERE id = :id",
+		"SELECT * FROM t WHERE id = :id AND name = :nam",
+		"SELECT * FROM t WHERE id = :id AND name = ?",
+	} {
+		if _, _, err = bindNamed(query, false, args); err == nil {
+			t.Errorf("%q: expected an error", query)
+		}
+	}
+}
+
+func TestNamedArgs(t *testing.T) {
+	srv := &fakeServer{}
+	addr, stop := startServer(t, srv)
+	defer stop()
+
+	db, err := sql.Open("mdb", "system:biglove@tcp("+addr+")/master?timeout=5s&interpolateParams=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	stmt, err := db.Prepare("AMEND t SET name = :name WHERE id = :id OR parent = :id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stmt.Close()
+
+	if _, err = stmt.Exec(sql.Named("id", 5), sql.Named("name", "x")); err != nil {
+		t.Fatal(err)
+	}
+	if want, last := `AMEND t SET name = "x" WHERE id = 5 OR parent = 5`, srv.statements[len(srv.statements)-1]; last != want {
+		t.Errorf("expected %q, got %q", want, last)
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	for _, tc := range []struct {
+		query string
+		want  []string
+	}{
+		{"SELECT 1", []string{"SELECT 1"}},
+		{"SELECT 1; SELECT 2;", []string{"SELECT 1", "SELECT 2"}},
+		{"INSERT INTO t VALUES ('a;b', [59]); -- done;\n", []string{"INSERT INTO t VALUES ('a;b', [59])"}},
+		{"SELECT 1 /* ; */; ;\n/* trailing */", []string{"SELECT 1 /* ; */"}},
+		{"  ; -- nothing", nil},
+	} {
+		if got := splitStatements(tc.query, false); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%q: expected %q, got %q", tc.query, tc.want, got)
+		}
+	}
+}
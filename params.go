@@ -0,0 +1,284 @@
+package mdb
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/blockpointSystems/protocol-buffers/v1/odbc"
+	"github.com/google/uuid"
+)
+
+var valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+
+// TypedValue is a statement argument bound to a bSQL datatype. The value
+// is converted to the datatype, and checked against its range, before the
+// statement is sent.
+type TypedValue struct {
+	Type  odbc.Datatype
+	Value interface{}
+}
+
+// Typed binds a statement argument to a bSQL datatype, e.g.
+//
+//	db.Exec("INSERT INTO t (flags) VALUES (?)", mdb.Typed(odbc.Datatype_UINT8, flags))
+//
+// reports ErrOutOfRange client-side if flags does not fit into a UINT8.
+func Typed(datatype odbc.Datatype, value interface{}) TypedValue {
+	return TypedValue{Type: datatype, Value: value}
+}
+
+// CheckNamedValue implements driver.NamedValueChecker. Named arguments are
+// bound to the :name and @name placeholders of the query.
+func (db *Conn) CheckNamedValue(nv *driver.NamedValue) (err error) {
+	nv.Value, err = convertArg(nv.Value)
+	return
+}
+
+// convertArg converts a statement argument to one of the values appendValue
+// encodes: nil, int64, uint64, float32, float64, bool, string, []byte,
+// json.RawMessage, time.Time or uuid.UUID, and timeOfDayValue for a typed
+// TIME argument.
+func convertArg(v interface{}) (driver.Value, error) {
+	switch x := v.(type) {
+	case nil, int64, uint64, float32, float64, bool, string, []byte, json.RawMessage, time.Time, uuid.UUID:
+		return v, nil
+	case TypedValue:
+		return convertTyped(x)
+	case [16]byte:
+		return uuid.UUID(x), nil
+	case *big.Int:
+		if x == nil {
+			return nil, nil
+		}
+		return bigIntValue(x)
+	case big.Int:
+		return bigIntValue(&x)
+	case driver.Valuer:
+		rv := reflect.ValueOf(v)
+		if rv.Kind() == reflect.Ptr && rv.IsNil() && rv.Type().Elem().Implements(valuerType) {
+			// Value would be called on a nil pointer
+			return nil, nil
+		}
+		value, err := x.Value()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := value.(driver.Valuer); ok {
+			return nil, fmt.Errorf("mdb: Value of %T returned another driver.Valuer", v)
+		}
+		return convertArg(value)
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		return convertArg(rv.Elem().Interface())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return rv.Uint(), nil
+	case reflect.Float32:
+		return float32(rv.Float()), nil
+	case reflect.Float64:
+		return rv.Float(), nil
+	case reflect.Bool:
+		return rv.Bool(), nil
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return rv.Bytes(), nil
+		}
+	case reflect.Array:
+		if rv.Len() == 16 && rv.Type().Elem().Kind() == reflect.Uint8 {
+			var id uuid.UUID
+			reflect.Copy(reflect.ValueOf(id[:]), rv)
+			return id, nil
+		}
+	}
+	return nil, fmt.Errorf("mdb: unsupported argument type %T", v)
+}
+
+// convertTyped converts the value of a TypedValue to the Go type encoding
+// its datatype.
+func convertTyped(tv TypedValue) (driver.Value, error) {
+	v, err := convertArg(tv.Value)
+	if err != nil || v == nil {
+		return v, err
+	}
+
+	switch tv.Type {
+	case odbc.Datatype_INT8, odbc.Datatype_INT16, odbc.Datatype_INT32, odbc.Datatype_INT64:
+		var n int64
+		switch x := v.(type) {
+		case int64:
+			n = x
+		case uint64:
+			if x > math.MaxInt64 {
+				return nil, outOfRange(v, tv.Type)
+			}
+			n = int64(x)
+		case string:
+			if n, err = strconv.ParseInt(x, 10, 64); err != nil {
+				return nil, rangeOrSyntax(err, v, tv.Type)
+			}
+		default:
+			return nil, mismatch(v, tv.Type)
+		}
+
+		if bits := integerBits(tv.Type); bits < 64 && (n < -1<<(bits-1) || n > 1<<(bits-1)-1) {
+			return nil, outOfRange(v, tv.Type)
+		}
+		return n, nil
+
+	case odbc.Datatype_UINT8, odbc.Datatype_UINT16, odbc.Datatype_UINT32, odbc.Datatype_UINT64:
+		var n uint64
+		switch x := v.(type) {
+		case int64:
+			if x < 0 {
+				return nil, outOfRange(v, tv.Type)
+			}
+			n = uint64(x)
+		case uint64:
+			n = x
+		case string:
+			if n, err = strconv.ParseUint(x, 10, 64); err != nil {
+				return nil, rangeOrSyntax(err, v, tv.Type)
+			}
+		default:
+			return nil, mismatch(v, tv.Type)
+		}
+
+		if bits := integerBits(tv.Type); bits < 64 && n > 1<<bits-1 {
+			return nil, outOfRange(v, tv.Type)
+		}
+		return n, nil
+
+	case odbc.Datatype_FLOAT32, odbc.Datatype_FLOAT64:
+		var f float64
+		switch x := v.(type) {
+		case int64:
+			f = float64(x)
+		case uint64:
+			f = float64(x)
+		case float32:
+			f = float64(x)
+		case float64:
+			f = x
+		default:
+			return nil, mismatch(v, tv.Type)
+		}
+
+		if tv.Type == odbc.Datatype_FLOAT32 {
+			if math.Abs(f) > math.MaxFloat32 && !math.IsInf(f, 0) {
+				return nil, outOfRange(v, tv.Type)
+			}
+			return float32(f), nil
+		}
+		return f, nil
+
+	case odbc.Datatype_BOOL:
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+
+	case odbc.Datatype_STRING:
+		switch x := v.(type) {
+		case string:
+			return x, nil
+		case []byte:
+			return string(x), nil
+		}
+
+	case odbc.Datatype_BYTEARRAY:
+		switch x := v.(type) {
+		case []byte:
+			return x, nil
+		case string:
+			return []byte(x), nil
+		}
+
+	case odbc.Datatype_UUID:
+		switch x := v.(type) {
+		case uuid.UUID:
+			return x, nil
+		case string:
+			id, err := uuid.Parse(x)
+			if err != nil {
+				return nil, fmt.Errorf("mdb: invalid UUID argument: %w", err)
+			}
+			return id, nil
+		case []byte:
+			id, err := uuid.FromBytes(x)
+			if err != nil {
+				return nil, fmt.Errorf("mdb: invalid UUID argument: %w", err)
+			}
+			return id, nil
+		}
+
+	case odbc.Datatype_TIMESTAMP, odbc.Datatype_DATETIME, odbc.Datatype_DATE, odbc.Datatype_TIME:
+		switch x := v.(type) {
+		case time.Time:
+			if tv.Type == odbc.Datatype_TIME {
+				return timeOfDayValue(x), nil
+			}
+			return x, nil
+		case string:
+			// The text form of Date and TimeOfDay
+			if tv.Type == odbc.Datatype_DATE {
+				_, err = ParseDate(x)
+			} else if tv.Type == odbc.Datatype_TIME {
+				_, err = ParseTimeOfDay(x)
+			} else {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			return x, nil
+		}
+
+	default:
+		return nil, fmt.Errorf("mdb: unsupported argument datatype %s", tv.Type)
+	}
+	return nil, mismatch(v, tv.Type)
+}
+
+// integerBits returns the width of an integer datatype.
+func integerBits(datatype odbc.Datatype) uint {
+	switch datatype {
+	case odbc.Datatype_INT8, odbc.Datatype_UINT8:
+		return 8
+	case odbc.Datatype_INT16, odbc.Datatype_UINT16:
+		return 16
+	case odbc.Datatype_INT32, odbc.Datatype_UINT32:
+		return 32
+	}
+	return 64
+}
+
+func outOfRange(v driver.Value, datatype odbc.Datatype) error {
+	return fmt.Errorf("%w: %v does not fit into %s", ErrOutOfRange, v, datatype)
+}
+
+// rangeOrSyntax converts an error of the strconv package.
+func rangeOrSyntax(err error, v driver.Value, datatype odbc.Datatype) error {
+	if errors.Is(err, strconv.ErrRange) {
+		return outOfRange(v, datatype)
+	}
+	return fmt.Errorf("mdb: invalid %s argument: %w", datatype, err)
+}
+
+func mismatch(v driver.Value, datatype odbc.Datatype) error {
+	return fmt.Errorf("mdb: can not use %T as %s", v, datatype)
+}
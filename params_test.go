@@ -0,0 +1,103 @@
+package mdb
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/blockpointSystems/protocol-buffers/v1/odbc"
+	"github.com/google/uuid"
+)
+
+type valuer string
+
+func (v valuer) Value() (driver.Value, error) { return string(v), nil }
+
+type ptrValuer struct{}
+
+func (*ptrValuer) Value() (driver.Value, error) { return "ptr", nil }
+
+func TestConvertArg(t *testing.T) {
+	var (
+		id     = uuid.MustParse("8e6f2b1c-4a55-4d9e-9a0b-2f1d3c4e5f60")
+		answer = 42
+		nilInt *int
+		nilVal *valuer
+	)
+
+	for _, tc := range []struct {
+		arg  interface{}
+		want string
+	}{
+		{int8(-8), "-8"},
+		{int16(-16), "-16"},
+		{int32(-32), "-32"},
+		{-64, "-64"},
+		{uint8(8), "8"},
+		{uint16(16), "16"},
+		{uint32(32), "32"},
+		{uint(math.MaxUint64), "18446744073709551615"},
+		{uint64(math.MaxUint64), "18446744073709551615"},
+		{float32(0.1), "0.1"},
+		{0.25, "0.25"},
+		{id, `"8e6f2b1c-4a55-4d9e-9a0b-2f1d3c4e5f60"`},
+		{[16]byte(id), `"8e6f2b1c-4a55-4d9e-9a0b-2f1d3c4e5f60"`},
+		{&answer, "42"},
+		{nilInt, "NULL"},
+		{valuer("v"), `"v"`},
+		{nilVal, "NULL"},
+		{&ptrValuer{}, `"ptr"`},
+		{sql.NullInt64{Int64: 7, Valid: true}, "7"},
+		{sql.NullString{}, "NULL"},
+		{Typed(odbc.Datatype_UINT8, 255), "255"},
+		{Typed(odbc.Datatype_INT16, int64(-32768)), "-32768"},
+		{Typed(odbc.Datatype_FLOAT32, 1), "1"},
+		{Typed(odbc.Datatype_UUID, id.String()), `"8e6f2b1c-4a55-4d9e-9a0b-2f1d3c4e5f60"`},
+		{Typed(odbc.Datatype_STRING, []byte("s")), `"s"`},
+		{Typed(odbc.Datatype_UINT64, nil), "NULL"},
+	} {
+		conn := &Conn{cfg: NewConfig()}
+
+		nv := driver.NamedValue{Value: tc.arg}
+		if err := conn.CheckNamedValue(&nv); err != nil {
+			t.Errorf("%#v: %v", tc.arg, err)
+			continue
+		}
+		got, err := conn.appendValue(nil, nv.Value)
+		if err != nil {
+			t.Errorf("%#v: %v", tc.arg, err)
+			continue
+		}
+		if string(got) != tc.want {
+			t.Errorf("%#v: expected %s, got %s", tc.arg, tc.want, got)
+		}
+	}
+}
+
+func TestConvertArgErrors(t *testing.T) {
+	for _, tc := range []struct {
+		arg        interface{}
+		outOfRange bool
+	}{
+		{Typed(odbc.Datatype_UINT8, 300), true},
+		{Typed(odbc.Datatype_UINT32, -1), true},
+		{Typed(odbc.Datatype_INT8, 128), true},
+		{Typed(odbc.Datatype_INT64, uint64(math.MaxUint64)), true},
+		{Typed(odbc.Datatype_FLOAT32, math.MaxFloat64), true},
+		{Typed(odbc.Datatype_BOOL, 1), false},
+		{Typed(odbc.Datatype_UUID, "not a uuid"), false},
+		{struct{}{}, false},
+		{[]int{1}, false},
+	} {
+		_, err := convertArg(tc.arg)
+		if err == nil {
+			t.Errorf("%#v: expected an error", tc.arg)
+			continue
+		}
+		if errors.Is(err, ErrOutOfRange) != tc.outOfRange {
+			t.Errorf("%#v: unexpected error %v", tc.arg, err)
+		}
+	}
+}
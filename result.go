@@ -5,17 +5,44 @@ package mdb
 type Result struct {
 	affectedRows int64
 	insertId     int64
+
+	// Per statement results of a multi-statement script
+	allAffectedRows []int64
+	allInsertIds    []int64
 }
 
 // LastInsertId returns the database's auto-generated ID
 // after, for example, an INSERT into a table with primary
-// key.
+// key. For a multi-statement script it is the ID of the
+// last statement.
 func (r *Result) LastInsertId() (int64, error) {
 	return r.insertId, nil
 }
 
 // RowsAffected returns the number of rows affected by the
-// query.
+// query. For a multi-statement script it is the count of
+// the last statement.
 func (r *Result) RowsAffected() (int64, error) {
 	return r.affectedRows, nil
 }
+
+// AllLastInsertIds returns the auto-generated ID of every statement of a
+// multi-statement script, in order. database/sql hides the Result, it is
+// returned by ExecContext on the driver connection handed out by
+// sql.Conn.Raw.
+func (r *Result) AllLastInsertIds() []int64 {
+	if r.allInsertIds == nil {
+		return []int64{r.insertId}
+	}
+	return append([]int64(nil), r.allInsertIds...)
+}
+
+// AllRowsAffected returns the number of rows affected by every statement of
+// a multi-statement script, in order. If a statement failed, it holds the
+// counts of the statements before it.
+func (r *Result) AllRowsAffected() []int64 {
+	if r.allAffectedRows == nil {
+		return []int64{r.affectedRows}
+	}
+	return append([]int64(nil), r.allAffectedRows...)
+}
@@ -1,6 +1,7 @@
 package mdb
 
 import (
+	"context"
 	"database/sql/driver"
 	"github.com/blockpointSystems/protocol-buffers/v1/odbc"
 	"io"
@@ -9,23 +10,31 @@ import (
 )
 
 type resultSet struct {
+	cfg         *Config
 	columnNames []string
 	rows        [][]driver.Value
 }
 
 // Rows is an iterator over an executed query's results.
 type Rows struct {
-	streamRecv *odbc.MDBService_QueryClient
+	// ctx is the context of the query, the rows are abandoned once it is
+	// done.
+	ctx  context.Context
+	recv func() (*odbc.QueryResponse, error)
 
 	schema *odbc.Schema
 
-	set     resultSet
-	nextSet *odbc.QueryResponse
+	set resultSet
 
 	setPos int32
 
 	close func() error
 	done  bool
+
+	// pending holds the remaining statements of a multi-statement script,
+	// open sends the next one.
+	pending []string
+	open    func(statement string) (func() (*odbc.QueryResponse, error), *odbc.QueryResponse, error)
 }
 
 // Columns returns the names of the columns. The number of
@@ -46,7 +55,7 @@ func (r *Rows) Close() error {
 	r.set.columnNames = nil
 	r.set.rows = nil
 
-	r.nextSet = nil
+	r.pending = nil
 	return r.close()
 }
 
@@ -60,13 +69,19 @@ func (r *Rows) Close() error {
 // should be taken when closing Rows not to modify
 // a buffer held in dest.
 func (r *Rows) Next(dest []driver.Value) (err error) {
+	if err = r.ctx.Err(); err != nil {
+		// Stop the query instead of handing out buffered rows
+		r.close()
+		return
+	}
+
 	pos := atomic.AddInt32(&r.setPos, 1) - 1
 	if int(pos) < len(r.set.rows) {
 		copy(dest, r.set.rows[pos])
 		return
 	}
 
-	err = r.NextResultSet()
+	err = r.fetch()
 	if err != nil {
 		return
 	}
@@ -74,24 +89,40 @@ func (r *Rows) Next(dest []driver.Value) (err error) {
 	return r.Next(dest)
 }
 
-// RowsNextResultSet extends the Rows interface by providing a way to signal
-// the driver to advance to the next result set.
-//type RowsNextResultSet interface {}
-
-// HasNextResultSet is called at the end of the current result set and
-// reports whether there is another result set after the current one.
-func (r *Rows) HasNextResultSet() bool {
-	if r.nextSet != nil {
-		return true
+// fetch receives the next batch of rows of the current result set. It
+// returns io.EOF once the result set is exhausted.
+func (r *Rows) fetch() error {
+	resp, err := r.recv()
+	if err != nil {
+		return err
 	}
 
-	nextResp, err := (*r.streamRecv).Recv()
+	r.set.buildNextResultSet(resp.GetRespSchema(), resp.GetResultSet())
+	r.done = resp.GetDone()
+	atomic.StoreInt32(&r.setPos, 0)
+	return nil
+}
+
+// start runs a statement and makes its rows the current result set.
+func (r *Rows) start(statement string) error {
+	recv, resp, err := r.open(statement)
 	if err != nil {
-		return false
+		return err
 	}
 
-	r.nextSet = nextResp
-	return true
+	r.recv = recv
+	r.schema = resp.GetRespSchema()
+	r.set = buildResultSet(r.set.cfg, resp.GetRespSchema(), resp.GetResultSet())
+	r.done = resp.GetDone()
+	atomic.StoreInt32(&r.setPos, 0)
+	return nil
+}
+
+// HasNextResultSet is called at the end of the current result set and
+// reports whether there is another result set after the current one. Every
+// statement of a multi-statement script is a result set.
+func (r *Rows) HasNextResultSet() bool {
+	return len(r.pending) > 0
 }
 
 // NextResultSet advances the driver to the next result set even
@@ -99,19 +130,18 @@ func (r *Rows) HasNextResultSet() bool {
 //
 // NextResultSet should return io.EOF when there are no more result sets.
 func (r *Rows) NextResultSet() error {
-	if r.HasNextResultSet() {
-		// Build the next result set
-		r.set.buildNextResultSet(r.nextSet.GetRespSchema(), r.nextSet.GetResultSet())
-		r.done = r.nextSet.GetDone()
-
-		// Clear the nextSet queue as it has been bumped up
-		atomic.StoreInt32(&r.setPos, 0)
-		r.nextSet = nil
+	if len(r.pending) == 0 {
+		return io.EOF
+	}
 
-		// Return nil
-		return nil
+	// Finish the query of the current statement
+	if err := r.close(); err != nil {
+		return err
 	}
-	return io.EOF
+
+	statement := r.pending[0]
+	r.pending = r.pending[1:]
+	return r.start(statement)
 }
 
 // ColumnTypeScanType may be implemented by Rows. It should return
@@ -138,6 +168,9 @@ func (r *Rows) ColumnTypeScanType(index int) reflect.Type {
 	case odbc.Datatype_INT64:
 		return scanTypeInt64
 	case odbc.Datatype_UINT64:
+		if r.set.cfg != nil && r.set.cfg.Uint64AsString {
+			return scanTypeString
+		}
 		return scanTypeUint64
 	case odbc.Datatype_FLOAT32:
 		return scanTypeFloat32
@@ -146,6 +179,9 @@ func (r *Rows) ColumnTypeScanType(index int) reflect.Type {
 	case odbc.Datatype_BOOL:
 		return scanTypeBoolean
 	case odbc.Datatype_TIMESTAMP, odbc.Datatype_DATETIME, odbc.Datatype_DATE, odbc.Datatype_TIME:
+		if r.set.cfg != nil && r.set.cfg.TemporalAsText {
+			return scanTypeString
+		}
 		return scanTypeNullTime
 	case odbc.Datatype_UUID:
 		return scanTypeString
@@ -268,7 +304,7 @@ func (rs *resultSet) buildNextResultSet(schema *odbc.Schema, set []*odbc.Row) {
 				continue
 			}
 			// Column is not null
-			rs.rows[i][j] = convertColumnToValue(col, schema.GetColumnType()[j])
+			rs.rows[i][j] = convertColumnToValue(col, schema.GetColumnType()[j], rs.cfg)
 		}
 	}
 
@@ -1,6 +1,9 @@
 package mdb
 
-import "database/sql/driver"
+import (
+	"context"
+	"database/sql/driver"
+)
 
 // Stmt is a prepared statement. It is bound to a Conn and not
 // used by multiple goroutines concurrently.
@@ -8,9 +11,18 @@ type Stmt struct {
 	conn *Conn
 	stmt 	   string
 	paramCount int
+
+	// cached statements are owned by the statement cache of the
+	// connection and outlive Close. refs counts the callers which have
+	// the statement open.
+	cached bool
+	refs   int
 }
 
-// Close closes the statement.
+// Close closes the statement. The MDB protocol has no server-side
+// statement handles, so only the statement is released and the connection
+// stays open. Statements held by the statement cache are released once
+// they are evicted.
 //
 // As of Go 1.1, a Stmt will not be closed if it's in use
 // by any queries.
@@ -21,9 +33,18 @@ func (s *Stmt) Close() (err error) {
 		return driver.ErrBadConn
 	}
 
-	err = s.conn.Close()
+	if s.refs > 0 {
+		s.refs--
+	}
+	if !s.cached && s.refs == 0 {
+		s.release()
+	}
+	return
+}
+
+// release detaches the statement from its connection.
+func (s *Stmt) release() {
 	s.conn = nil
-	return err
 }
 
 // NumInput returns the number of placeholder parameters.
@@ -44,6 +65,9 @@ func (s *Stmt) NumInput() int {
 //
 // Deprecated: Drivers should implement StmtExecContext instead (or additionally).
 func (s *Stmt) Exec(args []driver.Value) (driver.Result, error) {
+	if s.conn == nil {
+		return nil, ErrStmtClosed
+	}
 	return s.conn.Exec(s.stmt, args)
 }
 
@@ -52,5 +76,26 @@ func (s *Stmt) Exec(args []driver.Value) (driver.Result, error) {
 //
 // Deprecated: Drivers should implement StmtQueryContext instead (or additionally).
 func (s *Stmt) Query(args []driver.Value) (driver.Rows, error) {
+	if s.conn == nil {
+		return nil, ErrStmtClosed
+	}
 	return s.conn.Query(s.stmt, args)
-}
\ No newline at end of file
+}
+
+// ExecContext executes a query that doesn't return rows, such
+// as an INSERT or UPDATE.
+func (s *Stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if s.conn == nil {
+		return nil, ErrStmtClosed
+	}
+	return s.conn.ExecContext(ctx, s.stmt, args)
+}
+
+// QueryContext executes a query that may return rows, such as a
+// SELECT.
+func (s *Stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	if s.conn == nil {
+		return nil, ErrStmtClosed
+	}
+	return s.conn.QueryContext(ctx, s.stmt, args)
+}
@@ -0,0 +1,80 @@
+package mdb
+
+import (
+	"context"
+	"testing"
+)
+
+func TestStmtClose(t *testing.T) {
+	srv := &fakeServer{}
+	addr, stop := startServer(t, srv)
+	defer stop()
+
+	conn := connectTo(t, addr, "")
+	defer conn.Close()
+
+	stmt, err := conn.PrepareContext(context.Background(), "DELETE FROM numbers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = stmt.(*Stmt).ExecContext(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err = stmt.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if conn.IsClosed() {
+		t.Fatal("closing the statement closed the connection")
+	}
+	if _, err = stmt.(*Stmt).ExecContext(context.Background(), nil); err != ErrStmtClosed {
+		t.Fatalf("expected ErrStmtClosed, got %v", err)
+	}
+	if _, err = conn.ExecContext(context.Background(), "DELETE FROM numbers", nil); err != nil {
+		t.Fatalf("expected the connection to be usable, got %v", err)
+	}
+}
+
+func TestStmtCache(t *testing.T) {
+	addr, stop := startServer(t, &fakeServer{})
+	defer stop()
+
+	conn := connectTo(t, addr, "&stmtCacheSize=2")
+	defer conn.Close()
+
+	prepare := func(query string) *Stmt {
+		stmt, err := conn.Prepare(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return stmt.(*Stmt)
+	}
+
+	a := prepare("DELETE FROM a")
+	a.Close()
+	if prepare("DELETE FROM a") != a {
+		t.Fatal("expected the cached statement")
+	}
+	a.Close()
+
+	// a stays open while b and c push it out of the cache
+	a = prepare("DELETE FROM a")
+	prepare("DELETE FROM b").Close()
+	prepare("DELETE FROM c").Close()
+
+	if _, err := a.ExecContext(context.Background(), nil); err != nil {
+		t.Fatalf("expected the evicted statement to stay usable, got %v", err)
+	}
+	a.Close()
+	if _, err := a.ExecContext(context.Background(), nil); err != ErrStmtClosed {
+		t.Fatalf("expected ErrStmtClosed once closed, got %v", err)
+	}
+	if prepare("DELETE FROM a") == a {
+		t.Fatal("expected a new statement after eviction")
+	}
+
+	want := StmtCacheStats{Hits: 2, Misses: 4, Size: 2}
+	if stats := conn.StmtCacheStats(); stats != want {
+		t.Fatalf("expected %+v, got %+v", want, stats)
+	}
+}
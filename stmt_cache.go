@@ -0,0 +1,99 @@
+package mdb
+
+import "container/list"
+
+// StmtCacheStats reports the activity of the prepared statement cache of a
+// connection. It is read through database/sql's Conn.Raw:
+//
+//	conn.Raw(func(driverConn interface{}) error {
+//		stats = driverConn.(*mdb.Conn).StmtCacheStats()
+//		return nil
+//	})
+type StmtCacheStats struct {
+	Hits   uint64 // Prepares served from the cache
+	Misses uint64 // Prepares which built a new statement
+	Size   int    // Statements currently cached
+}
+
+// stmtCache is a least recently used cache of the prepared statements of a
+// connection, keyed by query text. Like the connection, it is not used
+// concurrently.
+type stmtCache struct {
+	capacity int
+	lru      *list.List // of *Stmt, most recently used first
+	stmts    map[string]*list.Element
+
+	hits, misses uint64
+}
+
+func newStmtCache(capacity int) *stmtCache {
+	return &stmtCache{
+		capacity: capacity,
+		lru:      list.New(),
+		stmts:    make(map[string]*list.Element, capacity),
+	}
+}
+
+// get returns the cached statement for query, or nil.
+func (c *stmtCache) get(query string) *Stmt {
+	if c == nil {
+		return nil
+	}
+
+	el, ok := c.stmts[query]
+	if !ok {
+		c.misses++
+		return nil
+	}
+
+	c.hits++
+	c.lru.MoveToFront(el)
+
+	stmt := el.Value.(*Stmt)
+	stmt.refs++
+	return stmt
+}
+
+// put adds stmt to the cache, evicting the least recently used statements
+// beyond the capacity.
+func (c *stmtCache) put(stmt *Stmt) {
+	stmt.cached = true
+	c.stmts[stmt.stmt] = c.lru.PushFront(stmt)
+
+	for c.lru.Len() > c.capacity {
+		c.remove(c.lru.Back())
+	}
+}
+
+// remove evicts a statement. A statement still open by its callers is
+// released once they close it.
+func (c *stmtCache) remove(el *list.Element) {
+	stmt := c.lru.Remove(el).(*Stmt)
+	delete(c.stmts, stmt.stmt)
+
+	stmt.cached = false
+	if stmt.refs == 0 {
+		stmt.release()
+	}
+}
+
+// clear releases every cached statement.
+func (c *stmtCache) clear() {
+	if c == nil {
+		return
+	}
+	for c.lru.Len() > 0 {
+		c.remove(c.lru.Back())
+	}
+}
+
+func (c *stmtCache) stats() StmtCacheStats {
+	if c == nil {
+		return StmtCacheStats{}
+	}
+	return StmtCacheStats{
+		Hits:   c.hits,
+		Misses: c.misses,
+		Size:   c.lru.Len(),
+	}
+}
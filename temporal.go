@@ -0,0 +1,290 @@
+package mdb
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Date is a calendar date without a time of day or time zone, the Go type
+// of a bSQL DATE.
+//
+//	var day mdb.Date
+//	err = row.Scan(&day)
+//
+// It scans both the time.Time and, with parseTime=false, the text form of
+// a DATE column, NULL scans as the zero Date. As an argument it is sent
+// as a YYYY-MM-DD string, the zero Date as NULL. In JSON it is a
+// "YYYY-MM-DD" string, the zero Date an empty string.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// DateOf returns the date of t in the location of t.
+func DateOf(t time.Time) Date {
+	y, m, d := t.Date()
+	return Date{Year: y, Month: m, Day: d}
+}
+
+// ParseDate parses a date in the YYYY-MM-DD form.
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(dateFormat, s)
+	if err != nil {
+		return Date{}, fmt.Errorf("mdb: invalid date %q", s)
+	}
+	return DateOf(t), nil
+}
+
+// String returns the date in the YYYY-MM-DD form.
+func (d Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// IsZero reports whether d is the zero Date.
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+// IsValid reports whether d is a date of the calendar, i.e. not
+// February 30th.
+func (d Date) IsValid() bool {
+	return DateOf(d.In(time.UTC)) == d
+}
+
+// In returns the time at midnight of d in loc.
+func (d Date) In(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+// AddDate returns the date years, months and days after d, normalized the
+// way time.Time.AddDate normalizes it.
+func (d Date) AddDate(years, months, days int) Date {
+	return DateOf(d.In(time.UTC).AddDate(years, months, days))
+}
+
+// AddDays returns the date n days after d.
+func (d Date) AddDays(n int) Date {
+	return d.AddDate(0, 0, n)
+}
+
+// DaysSince returns the number of days from e to d.
+func (d Date) DaysSince(e Date) int {
+	// time.Time.Sub saturates after 292 years, the Unix seconds do not
+	return int((d.In(time.UTC).Unix() - e.In(time.UTC).Unix()) / secondsPerDay)
+}
+
+// Before reports whether d is before e.
+func (d Date) Before(e Date) bool {
+	return d.In(time.UTC).Before(e.In(time.UTC))
+}
+
+// After reports whether d is after e.
+func (d Date) After(e Date) bool {
+	return e.Before(d)
+}
+
+// Scan implements sql.Scanner. A NULL column sets the zero Date.
+func (d *Date) Scan(src interface{}) (err error) {
+	switch v := src.(type) {
+	case nil:
+		*d = Date{}
+	case time.Time:
+		*d = DateOf(v)
+	case string:
+		*d, err = ParseDate(v)
+	case []byte:
+		*d, err = ParseDate(string(v))
+	default:
+		err = fmt.Errorf("mdb: can not scan %T into Date", src)
+	}
+	return
+}
+
+// Value implements driver.Valuer.
+func (d Date) Value() (driver.Value, error) {
+	if d.IsZero() {
+		return nil, nil
+	}
+	if !d.IsValid() {
+		return nil, fmt.Errorf("mdb: invalid date %s", d)
+	}
+	return d.String(), nil
+}
+
+// MarshalText implements encoding.TextMarshaler. The zero Date is
+// marshalled as empty text.
+func (d Date) MarshalText() ([]byte, error) {
+	if d.IsZero() {
+		return []byte{}, nil
+	}
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. Empty text is the zero
+// Date.
+func (d *Date) UnmarshalText(text []byte) (err error) {
+	if len(text) == 0 {
+		*d = Date{}
+		return nil
+	}
+	*d, err = ParseDate(string(text))
+	return
+}
+
+// TimeOfDay is a time of day without a date or time zone, the Go type of
+// a bSQL TIME. Like TIME it has a precision of one second.
+//
+// It scans both the time.Time and, with parseTime=false, the text form of
+// a TIME column. Its zero value is midnight, so it can not scan NULL, use
+// NullTimeOfDay for a nullable column. As an argument it is sent as a
+// hh:mm:ss string. In JSON it is a "hh:mm:ss" string.
+type TimeOfDay struct {
+	Hour   int
+	Minute int
+	Second int
+}
+
+const secondsPerDay = 24 * 60 * 60
+
+// TimeOfDayOf returns the time of day of t in the location of t.
+func TimeOfDayOf(t time.Time) TimeOfDay {
+	h, m, s := t.Clock()
+	return TimeOfDay{Hour: h, Minute: m, Second: s}
+}
+
+// ParseTimeOfDay parses a time of day in the hh:mm:ss form.
+func ParseTimeOfDay(s string) (TimeOfDay, error) {
+	t, err := time.Parse(timeOfDayFormat, s)
+	if err != nil {
+		return TimeOfDay{}, fmt.Errorf("mdb: invalid time of day %q", s)
+	}
+	return TimeOfDayOf(t), nil
+}
+
+// String returns the time of day in the hh:mm:ss form.
+func (t TimeOfDay) String() string {
+	return fmt.Sprintf("%02d:%02d:%02d", t.Hour, t.Minute, t.Second)
+}
+
+// IsValid reports whether t is a time of day, from 00:00:00 to 23:59:59.
+func (t TimeOfDay) IsValid() bool {
+	return 0 <= t.Hour && t.Hour < 24 && 0 <= t.Minute && t.Minute < 60 && 0 <= t.Second && t.Second < 60
+}
+
+// On returns the time of t on date d in loc.
+func (t TimeOfDay) On(d Date, loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, t.Hour, t.Minute, t.Second, 0, loc)
+}
+
+// Add returns the time of day d after t, wrapping around midnight. d is
+// truncated to whole seconds.
+func (t TimeOfDay) Add(d time.Duration) TimeOfDay {
+	s := (t.seconds() + int(d/time.Second)%secondsPerDay + secondsPerDay) % secondsPerDay
+	return TimeOfDay{Hour: s / 3600, Minute: s / 60 % 60, Second: s % 60}
+}
+
+// Sub returns the duration from u to t on the same day, it is negative if
+// u is after t.
+func (t TimeOfDay) Sub(u TimeOfDay) time.Duration {
+	return time.Duration(t.seconds()-u.seconds()) * time.Second
+}
+
+// Before reports whether t is before u.
+func (t TimeOfDay) Before(u TimeOfDay) bool {
+	return t.seconds() < u.seconds()
+}
+
+// After reports whether t is after u.
+func (t TimeOfDay) After(u TimeOfDay) bool {
+	return t.seconds() > u.seconds()
+}
+
+func (t TimeOfDay) seconds() int {
+	return t.Hour*3600 + t.Minute*60 + t.Second
+}
+
+// Scan implements sql.Scanner.
+func (t *TimeOfDay) Scan(src interface{}) (err error) {
+	switch v := src.(type) {
+	case nil:
+		err = errors.New("mdb: can not scan NULL into TimeOfDay, use NullTimeOfDay")
+	case time.Time:
+		*t = TimeOfDayOf(v)
+	case string:
+		*t, err = ParseTimeOfDay(v)
+	case []byte:
+		*t, err = ParseTimeOfDay(string(v))
+	default:
+		err = fmt.Errorf("mdb: can not scan %T into TimeOfDay", src)
+	}
+	return
+}
+
+// Value implements driver.Valuer.
+func (t TimeOfDay) Value() (driver.Value, error) {
+	if !t.IsValid() {
+		return nil, fmt.Errorf("mdb: invalid time of day %s", t)
+	}
+	return t.String(), nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (t TimeOfDay) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (t *TimeOfDay) UnmarshalText(text []byte) (err error) {
+	*t, err = ParseTimeOfDay(string(text))
+	return
+}
+
+// NullTimeOfDay is a TimeOfDay which may be NULL. Valid is false for NULL,
+// which is sent as NULL and is null in JSON.
+type NullTimeOfDay struct {
+	TimeOfDay TimeOfDay
+	Valid     bool
+}
+
+// Scan implements sql.Scanner.
+func (n *NullTimeOfDay) Scan(src interface{}) error {
+	if src == nil {
+		*n = NullTimeOfDay{}
+		return nil
+	}
+	err := n.TimeOfDay.Scan(src)
+	n.Valid = err == nil
+	return err
+}
+
+// Value implements driver.Valuer.
+func (n NullTimeOfDay) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.TimeOfDay.Value()
+}
+
+// MarshalJSON implements json.Marshaler.
+func (n NullTimeOfDay) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(n.TimeOfDay)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (n *NullTimeOfDay) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*n = NullTimeOfDay{}
+		return nil
+	}
+	err := json.Unmarshal(data, &n.TimeOfDay)
+	n.Valid = err == nil
+	return err
+}
@@ -0,0 +1,218 @@
+package mdb
+
+import (
+	"database/sql"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/blockpointSystems/protocol-buffers/v1/odbc"
+)
+
+func TestScanDateAndTimeOfDay(t *testing.T) {
+	date := []byte{0, 0, 2, 29}
+	binary.LittleEndian.PutUint16(date, 2024)
+
+	srv := &fakeServer{
+		query: func(req *odbc.QueryRequest, stream odbc.MDBService_QueryServer) error {
+			return stream.Send(&odbc.QueryResponse{
+				RespSchema: &odbc.Schema{
+					ColumnName: []string{"day", "at"},
+					ColumnType: []odbc.Datatype{odbc.Datatype_DATE, odbc.Datatype_TIME},
+				},
+				ResultSet: []*odbc.Row{{Columns: [][]byte{date, {23, 59, 1}}, NullColumnBitmap: []byte{0}}},
+				Done:      true,
+			})
+		},
+	}
+	addr, stop := startServer(t, srv)
+	defer stop()
+
+	for _, params := range []string{"", "&parseTime=false", "&loc=America%2FNew_York"} {
+		db, err := sql.Open("mdb", "system:biglove@tcp("+addr+")/master?timeout=5s"+params)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var (
+			day Date
+			at  TimeOfDay
+		)
+		if err = db.QueryRow("SELECT day, at FROM t").Scan(&day, &at); err != nil {
+			t.Fatalf("%s: %v", params, err)
+		}
+		if day != (Date{2024, time.February, 29}) || at != (TimeOfDay{23, 59, 1}) {
+			t.Errorf("%s: expected 2024-02-29 23:59:01, got %s %s", params, day, at)
+		}
+		db.Close()
+	}
+}
+
+func TestDateAndTimeOfDayArgs(t *testing.T) {
+	conn := &Conn{cfg: NewConfig()}
+
+	for _, tc := range []struct {
+		arg  interface{}
+		want string
+	}{
+		{Date{2024, time.February, 29}, `"2024-02-29"`},
+		{Date{}, "NULL"},
+		{&Date{2021, time.December, 31}, `"2021-12-31"`},
+		{TimeOfDay{7, 5, 0}, `"07:05:00"`},
+		{Typed(odbc.Datatype_DATE, Date{2024, time.February, 29}), `"2024-02-29"`},
+		{Typed(odbc.Datatype_TIME, TimeOfDay{}), `"00:00:00"`},
+	} {
+		v, err := convertArg(tc.arg)
+		if err != nil {
+			t.Fatalf("%v: %v", tc.arg, err)
+		}
+		got, err := conn.appendValue(nil, v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tc.want {
+			t.Errorf("%v: expected %s, got %s", tc.arg, tc.want, got)
+		}
+	}
+
+	for _, arg := range []interface{}{
+		Date{2023, time.February, 29},
+		TimeOfDay{24, 0, 0},
+		Typed(odbc.Datatype_DATE, "2024-13-01"),
+		Typed(odbc.Datatype_TIME, "12:00"),
+	} {
+		if _, err := convertArg(arg); err == nil {
+			t.Errorf("%v: expected an error", arg)
+		}
+	}
+}
+
+func TestDateArithmetic(t *testing.T) {
+	d := Date{2024, time.January, 31}
+
+	if got := d.AddDays(30); got != (Date{2024, time.March, 1}) {
+		t.Errorf("AddDays: got %s", got)
+	}
+	if got := d.AddDate(1, 1, 0); got != (Date{2025, time.March, 3}) {
+		t.Errorf("AddDate: got %s", got)
+	}
+	for _, tc := range []struct {
+		d, e Date
+		want int
+	}{
+		{Date{2025, time.January, 31}, d, 366},
+		{d, Date{2025, time.January, 31}, -366},
+		{Date{2000, time.January, 1}, Date{1, time.January, 1}, 730119},
+	} {
+		if got := tc.d.DaysSince(tc.e); got != tc.want {
+			t.Errorf("%s.DaysSince(%s): expected %d, got %d", tc.d, tc.e, tc.want, got)
+		}
+	}
+	if !d.Before(d.AddDays(1)) || d.After(d) {
+		t.Error("unexpected order")
+	}
+	if (Date{2024, time.February, 30}).IsValid() || !d.IsValid() {
+		t.Error("unexpected validity")
+	}
+	if got := d.In(time.UTC); !got.Equal(time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("In: got %v", got)
+	}
+}
+
+func TestTimeOfDayArithmetic(t *testing.T) {
+	at := TimeOfDay{23, 30, 0}
+
+	if got := at.Add(45 * time.Minute); got != (TimeOfDay{0, 15, 0}) {
+		t.Errorf("Add: got %s", got)
+	}
+	if got := at.Add(-24*time.Hour - time.Second); got != (TimeOfDay{23, 29, 59}) {
+		t.Errorf("Add: got %s", got)
+	}
+	if got := at.Sub(TimeOfDay{1, 0, 0}); got != 22*time.Hour+30*time.Minute {
+		t.Errorf("Sub: got %v", got)
+	}
+	if !at.After(TimeOfDay{1, 0, 0}) || at.Before(at) {
+		t.Error("unexpected order")
+	}
+	want := time.Date(2024, time.May, 1, 23, 30, 0, 0, time.UTC)
+	if got := at.On(Date{2024, time.May, 1}, time.UTC); !got.Equal(want) {
+		t.Errorf("On: got %v", got)
+	}
+}
+
+func TestDateAndTimeOfDayJSON(t *testing.T) {
+	type event struct {
+		Day Date      `json:"day"`
+		At  TimeOfDay `json:"at"`
+	}
+
+	in := event{Date{1999, time.December, 31}, TimeOfDay{23, 59, 59}}
+	b, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"day":"1999-12-31","at":"23:59:59"}`; string(b) != want {
+		t.Errorf("expected %s, got %s", want, b)
+	}
+
+	var out event
+	if err = json.Unmarshal(b, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out != in {
+		t.Errorf("expected %+v, got %+v", in, out)
+	}
+
+	// The zero Date, a NULL column, round trips as an empty string
+	in.Day = Date{}
+	if b, err = json.Marshal(in); err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"day":"","at":"23:59:59"}`; string(b) != want {
+		t.Errorf("expected %s, got %s", want, b)
+	}
+	out.Day = Date{2000, time.January, 1}
+	if err = json.Unmarshal(b, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out != in {
+		t.Errorf("expected %+v, got %+v", in, out)
+	}
+
+	if err = json.Unmarshal([]byte(`{"day":"31.12.1999"}`), &out); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestNullTimeOfDay(t *testing.T) {
+	var at TimeOfDay
+	if err := at.Scan(nil); err == nil {
+		t.Error("expected scanning NULL into TimeOfDay to fail")
+	}
+
+	var n NullTimeOfDay
+	if err := n.Scan(nil); err != nil || n.Valid {
+		t.Errorf("expected NULL, got %+v, %v", n, err)
+	}
+	if v, err := n.Value(); v != nil || err != nil {
+		t.Errorf("expected NULL to be sent as nil, got %v, %v", v, err)
+	}
+	if b, _ := json.Marshal(n); string(b) != "null" {
+		t.Errorf("expected null, got %s", b)
+	}
+
+	if err := n.Scan("00:00:00"); err != nil || !n.Valid {
+		t.Errorf("expected midnight, got %+v, %v", n, err)
+	}
+	if v, err := n.Value(); v != "00:00:00" || err != nil {
+		t.Errorf("expected midnight to be sent as 00:00:00, got %v, %v", v, err)
+	}
+
+	if err := json.Unmarshal([]byte(`"12:30:00"`), &n); err != nil || n != (NullTimeOfDay{TimeOfDay{12, 30, 0}, true}) {
+		t.Errorf("expected 12:30:00, got %+v, %v", n, err)
+	}
+	if err := json.Unmarshal([]byte("null"), &n); err != nil || n.Valid {
+		t.Errorf("expected NULL, got %+v, %v", n, err)
+	}
+}
@@ -1,6 +1,9 @@
 package mdb
 
-import "database/sql/driver"
+import (
+	"context"
+	"database/sql/driver"
+)
 
 // Tx is a transaction.
 type Tx struct {
@@ -21,7 +24,8 @@ func CreateTransaction(xactId uint64, xactCfg driver.TxOptions, dbConn *Conn) Tx
 
 func (xact Tx) Commit() (err error) {
 	if xact.Conn != nil && !xact.IsClosed() {
-		_, _, err = xact.exec("COMMIT")
+		_, _, err = xact.exec(context.Background(), "COMMIT")
+		xact.status &^= statusInTrans
 		xact.Conn = nil
 		return
 	}
@@ -30,7 +34,8 @@ func (xact Tx) Commit() (err error) {
 
 func (xact Tx) Rollback() (err error) {
 	if xact.Conn != nil && !xact.IsClosed() {
-		_, _, err = xact.exec("ROLLBACK")
+		_, _, err = xact.exec(context.Background(), "ROLLBACK")
+		xact.status &^= statusInTrans
 		return
 	}
 	return ErrInvalidConn
@@ -0,0 +1,147 @@
+package mdb
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+)
+
+// handshakeError wraps a failed TLS handshake. It reports itself as
+// non-temporary so a blocking dial gives up instead of retrying forever.
+type handshakeError struct {
+	err error
+
+	// refused is set when the server answered in plaintext (or hung up)
+	// instead of speaking TLS.
+	refused bool
+}
+
+func (e *handshakeError) Error() string   { return e.err.Error() }
+func (e *handshakeError) Unwrap() error   { return e.err }
+func (e *handshakeError) Temporary() bool { return false }
+
+// tlsCredentials are the gRPC transport credentials built from cfg.tls.
+type tlsCredentials struct {
+	credentials.TransportCredentials
+}
+
+func newTLSCredentials(cfg *tls.Config) credentials.TransportCredentials {
+	// credentials.NewTLS infers ServerName from the dialed authority if it
+	// has not been set by normalize.
+	return &tlsCredentials{credentials.NewTLS(cfg)}
+}
+
+func (c *tlsCredentials) ClientHandshake(ctx context.Context, authority string, rawConn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	conn, info, err := c.TransportCredentials.ClientHandshake(ctx, authority, rawConn)
+	if err != nil && ctx.Err() == nil {
+		var recordErr tls.RecordHeaderError
+		err = &handshakeError{
+			err:     err,
+			refused: errors.As(err, &recordErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF),
+		}
+	}
+	return conn, info, err
+}
+
+func (c *tlsCredentials) Clone() credentials.TransportCredentials {
+	return &tlsCredentials{c.TransportCredentials.Clone()}
+}
+
+// defaultDialTimeout bounds a dial when the DSN sets no timeout. The dial
+// blocks, without a bound a host which accepts connections but never
+// answers would hold up the failover to the next host forever.
+var defaultDialTimeout = 20 * time.Second
+
+// dial opens the gRPC channel to addr.
+//
+// If TLS is configured the channel is secured with it. A server which does
+// not speak TLS results in ErrNoTLS, unless tls=preferred was requested, in
+// which case the channel falls back to plaintext.
+func (c *connector) dial(ctx context.Context, addr string) (grpcConn *grpc.ClientConn, err error) {
+	timeout := c.cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultDialTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if c.cfg.tls == nil {
+		return grpc.DialContext(ctx, addr, c.dialOptions(grpc.WithInsecure())...)
+	}
+
+	grpcConn, err = grpc.DialContext(ctx, addr, c.dialOptions(
+		grpc.WithTransportCredentials(newTLSCredentials(c.cfg.tls)),
+	)...)
+	if err == nil {
+		return
+	}
+
+	var hsErr *handshakeError
+	if !errors.As(connectionErrorOrigin(err), &hsErr) {
+		return
+	}
+	if !hsErr.refused {
+		return nil, hsErr.err
+	}
+	if c.cfg.TLSConfig != "preferred" {
+		return nil, ErrNoTLS
+	}
+
+	return grpc.DialContext(ctx, addr, c.dialOptions(grpc.WithInsecure())...)
+}
+
+// dialOptions returns the options shared by every dial attempt. The dial
+// blocks until the channel is ready, or the dial timeout expires, so that
+// connection and handshake failures surface here rather than on the first
+// RPC.
+func (c *connector) dialOptions(creds grpc.DialOption) []grpc.DialOption {
+	opts := []grpc.DialOption{
+		creds,
+		grpc.WithBlock(),
+		grpc.FailOnNonTempDialError(true),
+		grpc.WithReturnConnectionError(),
+	}
+
+	// Plain tcp keeps the gRPC dialer, which honors proxy settings.
+	if dial := getDialContext(c.cfg.Net); dial != nil {
+		opts = append(opts, grpc.WithContextDialer(dial))
+	} else if c.cfg.Net != "tcp" {
+		opts = append(opts, grpc.WithContextDialer(c.dialNet))
+	}
+
+	// A socket path is not a valid authority.
+	if c.cfg.Net == "unix" {
+		opts = append(opts, grpc.WithAuthority("localhost"))
+	}
+
+	if c.cfg.KeepaliveTime > 0 || c.cfg.KeepaliveTimeout > 0 {
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                c.cfg.KeepaliveTime,
+			Timeout:             c.cfg.KeepaliveTimeout,
+			PermitWithoutStream: c.cfg.KeepalivePermitWithoutStream,
+		}))
+	}
+	return opts
+}
+
+// dialNet dials addr on the configured network.
+func (c *connector) dialNet(ctx context.Context, addr string) (net.Conn, error) {
+	var nd net.Dialer
+	return nd.DialContext(ctx, c.cfg.Net, addr)
+}
+
+// connectionErrorOrigin unwraps the transport error returned by a failed
+// blocking dial.
+func connectionErrorOrigin(err error) error {
+	if ce, ok := err.(interface{ Origin() error }); ok {
+		return ce.Origin()
+	}
+	return err
+}
@@ -0,0 +1,545 @@
+package mdb
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/blockpointSystems/protocol-buffers/v1/odbc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+)
+
+// fakeServer is a minimal in-process MDB service. The hooks override the
+// default behavior of the corresponding RPCs.
+type fakeServer struct {
+	odbc.UnimplementedMDBServiceServer
+
+	mu            sync.Mutex
+	inits         int
+	statements    []string
+	closedQueries int
+
+	exec  func(*odbc.ExecRequest) (*odbc.ExecResponse, error)
+	query func(*odbc.QueryRequest, odbc.MDBService_QueryServer) error
+}
+
+func (s *fakeServer) InitializeConnection(ctx context.Context, req *odbc.InitializationRequest) (*odbc.AuthPacket, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.inits++
+	return &odbc.AuthPacket{LoginId: uint64(s.inits), JWT: fmt.Sprintf("token-%d", s.inits)}, nil
+}
+
+func (s *fakeServer) Begin(ctx context.Context, req *odbc.XactRequest) (*odbc.XactResponse, error) {
+	return &odbc.XactResponse{XactId: 1}, nil
+}
+
+func (s *fakeServer) Exec(ctx context.Context, req *odbc.ExecRequest) (*odbc.ExecResponse, error) {
+	s.mu.Lock()
+	s.statements = append(s.statements, req.GetStatement())
+	s.mu.Unlock()
+
+	if s.exec != nil {
+		return s.exec(req)
+	}
+	return &odbc.ExecResponse{AffectedRows: 1}, nil
+}
+
+func (s *fakeServer) Query(req *odbc.QueryRequest, stream odbc.MDBService_QueryServer) error {
+	s.mu.Lock()
+	s.statements = append(s.statements, req.GetStatement())
+	s.mu.Unlock()
+
+	if s.query != nil {
+		return s.query(req, stream)
+	}
+	return stream.Send(&odbc.QueryResponse{Done: true})
+}
+
+func (s *fakeServer) Close(ctx context.Context, auth *odbc.AuthPacket) (*odbc.CloseResponse, error) {
+	return &odbc.CloseResponse{}, nil
+}
+
+func (s *fakeServer) CloseQuery(ctx context.Context, auth *odbc.AuthPacket) (*odbc.CloseQueryResponse, error) {
+	s.mu.Lock()
+	s.closedQueries++
+	s.mu.Unlock()
+
+	return &odbc.CloseQueryResponse{}, nil
+}
+
+func (s *fakeServer) closedQueryCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closedQueries
+}
+
+// startServer starts srv without TLS on a random local port.
+func startServer(t *testing.T, srv odbc.MDBServiceServer) (addr string, stop func()) {
+	return startServerOn(t, "tcp", "127.0.0.1:0", srv)
+}
+
+func startServerOn(t *testing.T, network, address string, srv odbc.MDBServiceServer) (addr string, stop func()) {
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := grpc.NewServer()
+	odbc.RegisterMDBServiceServer(server, srv)
+	go server.Serve(listener)
+
+	return listener.Addr().String(), server.Stop
+}
+
+// startTLSServer starts srv with TLS on a random local port.
+func startTLSServer(t *testing.T, config *tls.Config, srv odbc.MDBServiceServer) (addr string, stop func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := grpc.NewServer(grpc.Creds(credentials.NewTLS(config)))
+	odbc.RegisterMDBServiceServer(server, srv)
+	go server.Serve(listener)
+
+	return listener.Addr().String(), server.Stop
+}
+
+// testCA issues certificates for the TLS tests.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pool *x509.CertPool
+}
+
+func newTestCA(t *testing.T) *testCA {
+	ca := &testCA{pool: x509.NewCertPool()}
+	ca.cert, ca.key = ca.issue(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "mdb test CA"},
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	})
+	ca.pool.AddCert(ca.cert)
+	return ca
+}
+
+// issue signs template with the CA, or self-signs it if the CA has no
+// certificate yet.
+func (ca *testCA) issue(t *testing.T, template *x509.Certificate) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+
+	parent, signer := template, key
+	if ca.cert != nil {
+		parent, signer = ca.cert, ca.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+// serverCert returns a certificate valid for the given host names and IPs.
+func (ca *testCA) serverCert(t *testing.T, dnsNames []string, ips []net.IP) tls.Certificate {
+	cert, key := ca.issue(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "mdb test server"},
+		DNSNames:    dnsNames,
+		IPAddresses: ips,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	return tls.Certificate{Certificate: [][]byte{cert.Raw}, PrivateKey: key}
+}
+
+func (ca *testCA) clientCert(t *testing.T) tls.Certificate {
+	cert, key := ca.issue(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "system"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	return tls.Certificate{Certificate: [][]byte{cert.Raw}, PrivateKey: key}
+}
+
+// startSilentListener accepts connections but never answers, like a host
+// whose server hangs.
+func startSilentListener(t *testing.T) (addr string, stop func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		mu    sync.Mutex
+		conns []net.Conn
+	)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			conns = append(conns, conn)
+			mu.Unlock()
+		}
+	}()
+
+	return listener.Addr().String(), func() {
+		listener.Close()
+		mu.Lock()
+		for _, conn := range conns {
+			conn.Close()
+		}
+		mu.Unlock()
+	}
+}
+
+func TestDialTLSRefused(t *testing.T) {
+	addr, stop := startServer(t, &fakeServer{})
+	defer stop()
+
+	for _, tc := range []struct {
+		tls     string
+		wantErr error
+	}{
+		{"true", ErrNoTLS},
+		{"skip-verify", ErrNoTLS},
+		{"preferred", nil},
+		{"false", nil},
+	} {
+		cfg, err := ParseDSN("system:biglove@tcp(" + addr + ")/master?tls=" + tc.tls)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		grpcConn, err := newConnector(cfg).dial(ctx, cfg.Addr)
+		cancel()
+
+		if err != tc.wantErr {
+			t.Errorf("tls=%s: expected error %v, got %v", tc.tls, tc.wantErr, err)
+		}
+		if grpcConn != nil {
+			grpcConn.Close()
+		}
+	}
+}
+
+func TestConnectMutualTLS(t *testing.T) {
+	ca := newTestCA(t)
+	addr, stop := startTLSServer(t, &tls.Config{
+		Certificates: []tls.Certificate{ca.serverCert(t, []string{"localhost"}, nil)},
+		ClientCAs:    ca.pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}, &fakeServer{})
+	defer stop()
+	_, port, _ := net.SplitHostPort(addr)
+
+	if err := RegisterTLSConfig("mdb-test-mtls", &tls.Config{
+		RootCAs:      ca.pool,
+		Certificates: []tls.Certificate{ca.clientCert(t)},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	defer DeregisterTLSConfig("mdb-test-mtls")
+	if err := RegisterTLSConfig("mdb-test-no-cert", &tls.Config{RootCAs: ca.pool}); err != nil {
+		t.Fatal(err)
+	}
+	defer DeregisterTLSConfig("mdb-test-no-cert")
+
+	for _, tc := range []struct {
+		tls     string
+		wantErr bool
+	}{
+		{"mdb-test-mtls", false},
+		// The server requires a client certificate
+		{"mdb-test-no-cert", true},
+		// The test CA is not trusted by the system pool
+		{"true", true},
+	} {
+		cfg, err := ParseDSN("system:biglove@tcp(localhost:" + port + ")/master?timeout=1s&tls=" + tc.tls)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := newConnector(cfg)
+
+		conn, err := c.Connect(context.Background())
+		if (err != nil) != tc.wantErr {
+			t.Errorf("tls=%s: expected error %v, got %v", tc.tls, tc.wantErr, err)
+		}
+		if err == nil {
+			conn.Close()
+		}
+		c.Close()
+	}
+}
+
+func TestConnectTLSServerNamePerHost(t *testing.T) {
+	ca := newTestCA(t)
+
+	// Each server has a certificate for the name it is dialed by only
+	byName, stopByName := startTLSServer(t, &tls.Config{
+		Certificates: []tls.Certificate{ca.serverCert(t, []string{"localhost"}, nil)},
+	}, &fakeServer{})
+	defer stopByName()
+	byIP, stopByIP := startTLSServer(t, &tls.Config{
+		Certificates: []tls.Certificate{ca.serverCert(t, nil, []net.IP{net.IPv4(127, 0, 0, 1)})},
+	}, &fakeServer{})
+	defer stopByIP()
+	_, port, _ := net.SplitHostPort(byName)
+
+	if err := RegisterTLSConfig("mdb-test-ca", &tls.Config{RootCAs: ca.pool}); err != nil {
+		t.Fatal(err)
+	}
+	defer DeregisterTLSConfig("mdb-test-ca")
+
+	cfg, err := ParseDSN("system:biglove@tcp(localhost:" + port + "," + byIP + ")/master?timeout=5s&tls=mdb-test-ca")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newConnector(cfg)
+	defer c.Close()
+
+	for _, h := range c.hosts.hosts {
+		conn, err := c.connect(context.Background(), h)
+		if err != nil {
+			t.Errorf("%s: %v", h.addr, err)
+			continue
+		}
+		conn.Close()
+	}
+
+	// A server name fixed to the first host is rejected by the second
+	cfg.tls.ServerName = "localhost"
+	fixed := newConnector(cfg)
+	defer fixed.Close()
+	if conn, err := fixed.connect(context.Background(), fixed.hosts.hosts[1]); err == nil {
+		conn.Close()
+		t.Errorf("%s: expected the server name localhost to be rejected", byIP)
+	}
+}
+
+func TestConnectFailover(t *testing.T) {
+	addr, stop := startServer(t, &fakeServer{})
+	defer stop()
+
+	// Nothing listens on port 1, the connector has to move on to addr.
+	cfg, err := ParseDSN("system:biglove@tcp(127.0.0.1:1," + addr + ")/master?timeout=5s")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := newConnector(cfg)
+	conn, err := c.Connect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if got := conn.(*Conn).host.addr; got != addr {
+		t.Errorf("expected connection to %s, got %s", addr, got)
+	}
+
+	// The dead host is backing off and is tried last.
+	if candidates := c.hosts.candidates(); candidates[0].addr != addr {
+		t.Errorf("expected %s to be tried first, got %s", addr, candidates[0].addr)
+	}
+}
+
+func TestConnectFailoverSilentHost(t *testing.T) {
+	silent, stopSilent := startSilentListener(t)
+	defer stopSilent()
+	addr, stop := startServer(t, &fakeServer{})
+	defer stop()
+
+	defer func(timeout time.Duration) { defaultDialTimeout = timeout }(defaultDialTimeout)
+	defaultDialTimeout = 200 * time.Millisecond
+
+	// Without a timeout in the DSN the dial to the silent host is bounded
+	// by the default.
+	cfg, err := ParseDSN("system:biglove@tcp(" + silent + "," + addr + ")/master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newConnector(cfg)
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	conn, err := c.Connect(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if got := conn.(*Conn).host.addr; got != addr {
+		t.Errorf("expected connection to %s, got %s", addr, got)
+	}
+}
+
+func TestDialTimeout(t *testing.T) {
+	silent, stop := startSilentListener(t)
+	defer stop()
+
+	cfg, err := ParseDSN("system:biglove@tcp(" + silent + ")/master?timeout=200ms")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newConnector(cfg)
+	defer c.Close()
+
+	start := time.Now()
+	if _, err = c.Connect(context.Background()); err == nil {
+		t.Fatal("expected an error")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("expected Connect to give up after 200ms, took %v", elapsed)
+	}
+}
+
+func TestConnectCanceled(t *testing.T) {
+	cfg, err := ParseDSN("system:biglove@tcp(127.0.0.1:1)/master?timeout=5s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newConnector(cfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err = c.Connect(ctx); err == nil {
+		t.Fatal("expected an error")
+	}
+	if c.hosts.hosts[0].isDown(time.Now()) {
+		t.Error("host marked down for a canceled dial")
+	}
+}
+
+func TestConnectNetworks(t *testing.T) {
+	var (
+		socket             = filepath.Join(t.TempDir(), "mdb.sock")
+		unixAddr, stopUnix = startServerOn(t, "unix", socket, &fakeServer{})
+		tcpAddr, stopTCP   = startServer(t, &fakeServer{})
+	)
+	defer stopUnix()
+	defer stopTCP()
+
+	var dialed string
+	RegisterDialContext("tunnel", func(ctx context.Context, addr string) (net.Conn, error) {
+		dialed = addr
+		var nd net.Dialer
+		return nd.DialContext(ctx, "tcp", tcpAddr)
+	})
+	defer DeregisterDialContext("tunnel")
+
+	for _, dsn := range []string{
+		"system:biglove@unix(" + unixAddr + ")/master",
+		"system:biglove@tunnel(mdb.internal:5461)/master",
+	} {
+		conn, err := (&MDBDriver{}).Open(dsn)
+		if err != nil {
+			t.Errorf("%s: %v", dsn, err)
+			continue
+		}
+		conn.Close()
+	}
+
+	if dialed != "mdb.internal:5461" {
+		t.Errorf("expected the tunnel dialer to be called with mdb.internal:5461, got %q", dialed)
+	}
+}
+
+func TestConnectorSharesChannel(t *testing.T) {
+	addr, stop := startServer(t, &fakeServer{})
+	defer stop()
+
+	cfg, err := ParseDSN("system:biglove@tcp(" + addr + ")/master?timeout=5s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newConnector(cfg)
+
+	var conns [2]*Conn
+	for i := range conns {
+		conn, err := c.Connect(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		conns[i] = conn.(*Conn)
+	}
+
+	channel := conns[0].channel
+	if conns[1].channel != channel {
+		t.Fatal("expected the connections to share a channel")
+	}
+
+	conns[0].Close()
+	if channel.GetState() == connectivity.Shutdown {
+		t.Fatal("channel closed while still in use")
+	}
+	conns[1].Close()
+	if channel.GetState() != connectivity.Shutdown {
+		t.Fatal("expected the channel to be closed with its last connection")
+	}
+
+	if err = c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = c.Connect(context.Background()); err != errConnectorClosed {
+		t.Fatalf("expected errConnectorClosed, got %v", err)
+	}
+}
+
+func TestCloseReleasesChannelOnError(t *testing.T) {
+	addr, stop := startServer(t, &fakeServer{})
+
+	cfg, err := ParseDSN("system:biglove@tcp(" + addr + ")/master?timeout=5s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newConnector(cfg)
+	defer c.Close()
+
+	conn, err := c.Connect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	mdbConn := conn.(*Conn)
+	channel := mdbConn.channel
+
+	// Closing the orphaned query fails once the server is gone.
+	mdbConn.SetActiveQuery()
+	stop()
+
+	if err = mdbConn.Close(); err == nil {
+		t.Fatal("expected an error")
+	}
+	if mdbConn.release != nil || channel.GetState() != connectivity.Shutdown {
+		t.Error("expected the channel to be released")
+	}
+}
@@ -2,6 +2,7 @@ package mdb
 
 import (
 	"crypto/tls"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"strconv"
@@ -89,6 +90,51 @@ func parseBool(input string) (value bool, valid bool) {
 	return
 }
 
+// appendByteArray appends the BYTE ARRAY literal of v to buf. Short arrays
+// are written as a list of their bytes, e.g. [1, 2, 3], longer ones as a
+// hex literal, e.g. 0x010203.
+func appendByteArray(buf, v []byte) []byte {
+	if len(v) > maxByteArrayList {
+		buf = append(buf, "0x"...)
+		return append(buf, hex.EncodeToString(v)...)
+	}
+
+	buf = append(buf, '[')
+	for i, b := range v {
+		if i > 0 {
+			buf = append(buf, ", "...)
+		}
+		buf = strconv.AppendUint(buf, uint64(b), 10)
+	}
+	return append(buf, ']')
+}
+
+// parseUseStatement returns the database selected by a USE statement.
+func parseUseStatement(query string) (dbName string, isUse bool) {
+	fields := strings.Fields(strings.TrimSuffix(strings.TrimSpace(query), ";"))
+	if len(fields) != 2 || !strings.EqualFold(fields[0], "USE") {
+		return "", false
+	}
+	return fields[1], true
+}
+
+// timeOfDayValue is an argument bound to TIME, only its time of day is sent.
+type timeOfDayValue time.Time
+
+// isTimeOfDay reports whether t is the value of a TIME column, which is
+// decoded on January 1st of year 0.
+func isTimeOfDay(t time.Time) bool {
+	year, month, day := t.Date()
+	return year == 0 && month == time.January && day == 1
+}
+
+// appendTimeOfDay appends the time of day of t as a quoted hh:mm:ss string.
+func appendTimeOfDay(buf []byte, t time.Time) []byte {
+	buf = append(buf, '"')
+	buf = t.AppendFormat(buf, timeOfDayFormat)
+	return append(buf, '"')
+}
+
 func appendDateTime(buf []byte, t time.Time) ([]byte, error) {
 	year, month, day := t.Date()
 	hour, min, sec := t.Clock()
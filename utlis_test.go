@@ -0,0 +1,121 @@
+package mdb
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/blockpointSystems/protocol-buffers/v1/odbc"
+)
+
+// parseByteArray decodes a BYTE ARRAY literal written by appendByteArray.
+func parseByteArray(t *testing.T, literal string) []byte {
+	if strings.HasPrefix(literal, "0x") {
+		v, err := hex.DecodeString(literal[2:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	if literal[0] != '[' || literal[len(literal)-1] != ']' {
+		t.Fatalf("malformed byte array literal %q", literal)
+	}
+	v := []byte{}
+	for _, field := range strings.Split(literal[1:len(literal)-1], ", ") {
+		if field == "" {
+			continue
+		}
+		b, err := strconv.ParseUint(field, 10, 8)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v = append(v, byte(b))
+	}
+	return v
+}
+
+func TestAppendByteArray(t *testing.T) {
+	for _, tc := range []struct {
+		v    []byte
+		want string
+	}{
+		{[]byte{}, "[]"},
+		{[]byte{100}, "[100]"},
+		{[]byte{0, 1, 255}, "[0, 1, 255]"},
+		{bytes.Repeat([]byte{0xab}, maxByteArrayList+1), "0x" + strings.Repeat("ab", maxByteArrayList+1)},
+	} {
+		if got := string(appendByteArray(nil, tc.v)); got != tc.want {
+			t.Errorf("%v: expected %s, got %s", tc.v, tc.want, got)
+		}
+	}
+}
+
+func TestByteArrayRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, n := range []int{0, 1, 20, maxByteArrayList, maxByteArrayList + 1, 1024} {
+		v := make([]byte, n)
+		rnd.Read(v)
+
+		literal := string(appendByteArray(nil, v))
+		got := convertColumnToValue(parseByteArray(t, literal), odbc.Datatype_BYTEARRAY, nil)
+		if !bytes.Equal(got.([]byte), v) {
+			t.Errorf("%d bytes: %s does not round trip", n, literal)
+		}
+	}
+}
+
+func TestScanRawBytes(t *testing.T) {
+	col := []byte{1, 2, 3}
+	srv := &fakeServer{
+		query: func(req *odbc.QueryRequest, stream odbc.MDBService_QueryServer) error {
+			return stream.Send(&odbc.QueryResponse{
+				RespSchema: &odbc.Schema{
+					ColumnName: []string{"hash", "salt"},
+					ColumnType: []odbc.Datatype{odbc.Datatype_BYTEARRAY, odbc.Datatype_BYTEARRAY},
+				},
+				// salt is null
+				ResultSet: []*odbc.Row{{Columns: [][]byte{col, nil}, NullColumnBitmap: []byte{0x02}}},
+				Done:      true,
+			})
+		},
+	}
+	addr, stop := startServer(t, srv)
+	defer stop()
+
+	db, err := sql.Open("mdb", "system:biglove@tcp("+addr+")/master?timeout=5s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	rows, err := db.QueryContext(context.Background(), "SELECT hash, salt FROM user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		t.Fatal(rows.Err())
+	}
+	var hash, salt sql.RawBytes
+	if err = rows.Scan(&hash, &salt); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(hash, col) || salt != nil {
+		t.Errorf("expected %v and nil, got %v and %v", col, hash, salt)
+	}
+
+	// The decoded column is handed out without a copy
+	var set resultSet
+	set.columnNames = []string{"hash"}
+	set.buildNextResultSet(&odbc.Schema{ColumnType: []odbc.Datatype{odbc.Datatype_BYTEARRAY}}, []*odbc.Row{{Columns: [][]byte{col}, NullColumnBitmap: []byte{0}}})
+	if &set.rows[0][0].([]byte)[0] != &col[0] {
+		t.Error("expected the byte array to share the memory of the response")
+	}
+}